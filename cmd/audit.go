@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"sync"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/audit"
+	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/spf13/cobra"
+)
+
+// auditLogPath is the resolved audit log location (config/env/flag).
+var auditLogPath = audit.DefaultPath()
+
+// auditMu serialises appends from concurrent operations (e.g. bulk delete),
+// since each entry chains to the previous one.
+var auditMu sync.Mutex
+
+// recordAudit appends a mutating API call to the audit log. Secrets in payload
+// are redacted. Failures to write the log are reported but never fatal.
+func recordAudit(cmd *cobra.Command, cl *client.Client, token, method, path string, payload any, resp *client.HTTPResponse, callErr error) {
+	if auditLogPath == "" {
+		return
+	}
+	e := audit.Entry{
+		OSUser:   osUsername(),
+		APIUser:  client.TokenSubject(token),
+		Profile:  profileName,
+		Command:  cmd.CommandPath(),
+		Method:   method,
+		Endpoint: cl.APIBase() + path,
+		Payload:  audit.Redact(payload),
+	}
+	if resp != nil {
+		e.Status = resp.StatusCode
+	}
+	if callErr != nil {
+		e.Error = callErr.Error()
+	}
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	if err := audit.Append(auditLogPath, e); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: could not write audit log:", err)
+	}
+}
+
+func osUsername() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if v, ok := getenvOpt("USER"); ok {
+		return v
+	}
+	return getenv("USERNAME", "unknown")
+}
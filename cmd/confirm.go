@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/secretinput"
+)
+
+// confirm asks a yes/no question on the terminal. assumeYes (--yes) skips the
+// prompt; without a TTY the answer is an error telling the user about --yes.
+func confirm(question string, assumeYes bool) error {
+	if assumeYes {
+		return nil
+	}
+	if !secretinput.IsTerminal() {
+		return fmt.Errorf("confirmation required: re-run with --yes")
+	}
+	ans, err := secretinput.ReadLine(question + " [y/N]")
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(ans)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("aborted")
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/hostgw"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var doctorColumns = []output.Column{
+	output.Col("CHECK", "check"),
+	output.Col("VALUE", "value"),
+	output.Col("DETAIL", "detail"),
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Explain the detected container runtime and Docker host gateway",
+	Long: "Detect the container runtime (Docker or Podman) and the address containers use\n" +
+		"to reach this host, and show which name localhost is rewritten to. Set\n" +
+		"docker_host: auto (or --docker-host auto) to use the detected value.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		det := hostgw.Detector{}.Detect()
+		findings := det.Findings
+		for _, f := range findings {
+			if f.Check == hostgw.Warning {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", f.Detail)
+			}
+		}
+
+		auto := strings.EqualFold(strings.TrimSpace(dockerHostSetting), "auto")
+		switch {
+		case auto:
+			findings = append(findings, hostgw.Finding{Check: "docker host", Value: cfg.DockerHostGatewayName, Detail: "docker_host is auto: using the detected gateway"})
+		case dockerHostSetting == det.Target:
+			findings = append(findings, hostgw.Finding{Check: "docker host", Value: dockerHostSetting, Detail: "configured; matches the detected gateway"})
+		default:
+			findings = append(findings, hostgw.Finding{Check: "docker host", Value: dockerHostSetting, Detail: "configured; detection suggests " + det.Target + " (set docker_host: auto to use it)"})
+		}
+		rewrite := "on"
+		if !cfg.RewriteLocalhost {
+			rewrite = "off"
+		}
+		findings = append(findings, hostgw.Finding{Check: "localhost rewrite", Value: rewrite, Detail: "--rewrite-localhost / rewrite_localhost"})
+
+		b, err := json.Marshal(findings)
+		if err != nil {
+			return err
+		}
+		return printBody(b, doctorColumns)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
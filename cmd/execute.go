@@ -1,14 +1,80 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"strings"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
 )
 
 // Execute runs the root command.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, "Error:", err)
+	rootCmd.SilenceErrors = true
+	err := rootCmd.Execute()
+	// Results of a partly failed command (some deletions, some register
+	// entries) are still written, before the error is reported.
+	if err == nil || resultBuf.Len() > 0 {
+		if ferr := flushOutputFile(); ferr != nil {
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", ferr)
+			} else {
+				err = ferr
+			}
+		}
+	}
+	if err != nil {
+		if structuredOutput() {
+			writeJSONError(err)
+		} else {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
 		os.Exit(1)
 	}
 }
+
+// structuredOutput reports whether a machine-readable format was requested
+// explicitly, in which case errors are written as JSON too.
+func structuredOutput() bool {
+	format := outputFormat
+	if format == "" {
+		format = flagOutput // flag parsing may have failed before buildConfig
+	}
+	name, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(format)), "=")
+	switch name {
+	case output.JSON, output.Pretty, output.NDJSON, output.YAML:
+		return true
+	}
+	return false
+}
+
+// jsonError is the shape of errors written with structured output.
+type jsonError struct {
+	Code    string `json:"code"`
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
+func writeJSONError(err error) {
+	je := jsonError{Code: "error", Message: err.Error()}
+	var apiErr *client.APIError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &apiErr):
+		je.Code = apiErr.Code()
+		je.Status = apiErr.StatusCode
+		je.Message = apiErr.Message()
+		je.Details = apiErr.Details()
+		je.Path = apiErr.Path
+	case errors.As(err, &netErr):
+		je.Code = "network_error"
+	}
+	b, _ := json.Marshal(map[string]any{"error": je})
+	fmt.Fprintln(os.Stderr, string(b))
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/audit"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	histUser    string
+	histCommand string
+	histSince   time.Duration
+	histLimit   int
+	histFailed  bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the local audit log of mutating commands",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := audit.Read(auditLogPath)
+		if err != nil {
+			return fmt.Errorf("read audit log %s: %w", auditLogPath, err)
+		}
+
+		var shown []audit.Entry
+		cutoff := time.Time{}
+		if histSince > 0 {
+			cutoff = time.Now().Add(-histSince)
+		}
+		for _, e := range entries {
+			if histUser != "" && e.OSUser != histUser && e.APIUser != histUser {
+				continue
+			}
+			if histCommand != "" && !strings.Contains(e.Command, histCommand) {
+				continue
+			}
+			if !cutoff.IsZero() && e.Time.Before(cutoff) {
+				continue
+			}
+			if histFailed && e.Error == "" && e.Status > 0 && e.Status < 300 {
+				continue
+			}
+			shown = append(shown, e)
+		}
+		if histLimit > 0 && len(shown) > histLimit {
+			shown = shown[len(shown)-histLimit:]
+		}
+
+		if shown == nil {
+			shown = []audit.Entry{}
+		}
+		b, err := json.Marshal(shown)
+		if err != nil {
+			return err
+		}
+		return printBody(b, historyColumns)
+	},
+}
+
+var historyVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the audit log hash chain for tampering",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := audit.Verify(auditLogPath)
+		if err != nil {
+			return fmt.Errorf("audit log %s: %w", auditLogPath, err)
+		}
+		fmt.Printf("OK: %d entries verified in %s\n", n, auditLogPath)
+		return nil
+	},
+}
+
+var historyColumns = []output.Column{
+	output.Col("TIME", "time"),
+	output.Col("OS USER", "os_user"),
+	output.Col("API USER", "api_user"),
+	output.Col("PROFILE", "profile"),
+	output.Col("COMMAND", "command"),
+	output.Col("METHOD", "method"),
+	output.Col("ENDPOINT", "endpoint"),
+	output.Col("STATUS", "status"),
+	output.Col("ERROR", "error"),
+	output.WideCol("PAYLOAD", "payload"),
+	output.WideCol("HASH", "hash"),
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyVerifyCmd)
+
+	historyCmd.Flags().StringVar(&histUser, "user", "", "Only entries by this OS or API user")
+	historyCmd.Flags().StringVar(&histCommand, "command", "", "Only entries whose command contains this text (e.g. 'machines delete')")
+	historyCmd.Flags().DurationVar(&histSince, "since", 0, "Only entries newer than this (e.g. 24h)")
+	historyCmd.Flags().IntVar(&histLimit, "limit", 0, "Show at most the last N matching entries")
+	historyCmd.Flags().BoolVar(&histFailed, "failed", false, "Only failed calls")
+}
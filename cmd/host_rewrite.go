@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/rewrite"
+)
+
+// rewriteHost applies the host rewrite rules to host and tells the user on
+// stderr when it changed. Results carry the returned rewrite as well.
+func rewriteHost(eng *rewrite.Engine, name, host string) (string, *rewrite.Applied) {
+	h, applied := eng.Rewrite(host)
+	if applied != nil {
+		fmt.Fprintf(os.Stderr, "%s: host %s\n", name, applied)
+	}
+	return h, applied
+}
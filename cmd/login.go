@@ -1,19 +1,19 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
+	"github.com/Jeomhps/projet-iac-cli/internal/secretinput"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 )
 
 var (
 	loginUsername string
-	loginPassword string
+	loginPassword *secretFlags
 )
 
 var loginCmd = &cobra.Command{
@@ -23,22 +23,22 @@ var loginCmd = &cobra.Command{
 		cl := client.New(cfg)
 
 		u := strings.TrimSpace(loginUsername)
-		p := loginPassword
-
-		reader := bufio.NewReader(os.Stdin)
 		if u == "" {
-			fmt.Print("Username: ")
-			uu, _ := reader.ReadString('\n')
-			u = strings.TrimSpace(uu)
-		}
-		if p == "" {
-			fmt.Print("Password: ")
-			b, err := term.ReadPassword(int(os.Stdin.Fd()))
-			fmt.Println()
+			if loginPassword.stdin() {
+				return fmt.Errorf("--username is required with --password-stdin")
+			}
+			if !secretinput.IsTerminal() {
+				return fmt.Errorf("--username is required when stdin is not a TTY")
+			}
+			uu, err := secretinput.ReadLine("Username")
 			if err != nil {
-				return fmt.Errorf("reading password: %w", err)
+				return fmt.Errorf("reading username: %w", err)
 			}
-			p = string(b)
+			u = uu
+		}
+		p, err := loginPassword.resolve("Password", false, true)
+		if err != nil {
+			return err
 		}
 		if u == "" || p == "" {
 			return fmt.Errorf("username and password are required")
@@ -51,17 +51,29 @@ var loginCmd = &cobra.Command{
 		if err := cl.SaveToken(token, exp); err != nil {
 			return err
 		}
+		if cfg.RememberCredentials {
+			if err := cl.SaveCredentials(u, p); err != nil {
+				fmt.Fprintln(os.Stderr, "Warning: credentials not remembered:", err)
+			} else {
+				defer fmt.Println("Credentials stored in OS keychain for automatic re-login.")
+			}
+		} else if err := cl.DeleteCredentials(); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: could not remove stored credentials:", err)
+		}
 
 		if cl.UsingKeychain() {
 			fmt.Println("Logged in. Token stored in OS keychain.")
 		} else {
 			fmt.Println("Logged in. Token cached at:", cfg.TokenFile)
 		}
+		if exp != nil {
+			fmt.Println("Token expires", output.HumanTime(*exp))
+		}
 		return nil
 	},
 }
 
 func init() {
 	loginCmd.Flags().StringVarP(&loginUsername, "username", "u", "", "Username")
-	loginCmd.Flags().StringVarP(&loginPassword, "password", "p", "", "Password (omit to prompt securely)")
+	loginPassword = addSecretFlags(loginCmd, "password", "p", "password", true)
 }
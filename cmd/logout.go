@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/Jeomhps/projet-iac-cli/internal/client"
 	"github.com/spf13/cobra"
 )
@@ -15,7 +13,9 @@ var logoutCmd = &cobra.Command{
 		if err := cl.DeleteToken(); err != nil {
 			return err
 		}
-		fmt.Println("Logged out; cached token removed.")
-		return nil
+		if err := cl.DeleteCredentials(); err != nil {
+			return err
+		}
+		return printResult(result{Action: "logout", Resource: "token", Name: cfg.APIBase, Status: statusOK})
 	},
 }
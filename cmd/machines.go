@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/Jeomhps/projet-iac-cli/internal/client"
-	"github.com/Jeomhps/projet-iac-cli/internal/output"
+	"github.com/Jeomhps/projet-iac-cli/internal/labels"
+	"github.com/Jeomhps/projet-iac-cli/internal/rewrite"
+	"github.com/Jeomhps/projet-iac-cli/internal/sshkey"
 	"github.com/Jeomhps/projet-iac-cli/internal/types"
 	"github.com/spf13/cobra"
 )
@@ -18,17 +21,7 @@ var machinesListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List machines",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cl := client.New(cfg)
-		token, err := cl.GetToken()
-		if err != nil {
-			return err
-		}
-		resp, err := cl.Get("/machines", token)
-		if err != nil {
-			return err
-		}
-		fmt.Println(output.FormatJSON(resp.Body, colorMode))
-		return nil
+		return runList(cmd, "/machines", machineColumns, "name")
 	},
 }
 
@@ -37,15 +30,34 @@ var (
 	mAddHost     string
 	mAddPort     int
 	mAddUser     string
-	mAddPassword string
+	mAddPassword *secretFlags
+	mAddLabels   []string
+	mAddKeyFile  string
+	mAddPass     *secretFlags
 )
 
 var machinesAddCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add a machine (admin)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if mAddName == "" || mAddHost == "" || mAddPort <= 0 || mAddUser == "" || mAddPassword == "" {
-			return fmt.Errorf("all fields required: --name --host --port --user --password")
+		if mAddName == "" || mAddHost == "" || mAddPort <= 0 || mAddUser == "" {
+			return fmt.Errorf("all fields required: --name --host --port --user")
+		}
+		lbls, _, err := labels.ParseAssignments(mAddLabels, false)
+		if err != nil {
+			return err
+		}
+		// With a key the password is optional and not prompted for.
+		var password, passphrase string
+		if mAddKeyFile == "" || mAddPassword.provided() {
+			if password, err = mAddPassword.resolve("SSH password", false, true); err != nil {
+				return err
+			}
+		}
+		if mAddPass.provided() {
+			if passphrase, err = mAddPass.resolve("SSH key passphrase", false, true); err != nil {
+				return err
+			}
 		}
 		cl := client.New(cfg)
 		token, err := cl.GetToken()
@@ -53,44 +65,43 @@ var machinesAddCmd = &cobra.Command{
 			return err
 		}
 		m := types.MachineCreate{
-			Name:     mAddName,
-			Host:     mAddHost,
-			Port:     mAddPort,
-			User:     mAddUser,
-			Password: mAddPassword,
+			Name:           mAddName,
+			Host:           mAddHost,
+			Port:           mAddPort,
+			User:           mAddUser,
+			Password:       password,
+			PrivateKeyFile: mAddKeyFile,
+			Passphrase:     passphrase,
 		}
-		if cl.ShouldRewrite(m.Host) {
-			m.Host = cfg.DockerHostGatewayName
+		err = loadMachineKey(&m, "")
+		if errors.Is(err, sshkey.ErrPassphraseNeeded) && !mAddPass.provided() {
+			if m.Passphrase, err = mAddPass.resolve("SSH key passphrase", false, true); err == nil {
+				err = loadMachineKey(&m, "")
+			}
 		}
-		resp, err := cl.PostJSON("/machines", token, m)
 		if err != nil {
 			return err
 		}
-		fmt.Println(output.FormatJSON(resp.Body, colorMode))
-		return nil
-	},
-}
-
-var mDelName string
-
-var machinesDelCmd = &cobra.Command{
-	Use:   "delete",
-	Short: "Delete a machine (admin)",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if mDelName == "" {
-			return fmt.Errorf("--name is required")
+		if len(lbls) > 0 {
+			m.Labels = lbls
 		}
-		cl := client.New(cfg)
-		token, err := cl.GetToken()
+		eng, err := cfg.HostRewriter()
 		if err != nil {
 			return err
 		}
-		_, err = cl.Delete("/machines/"+mDelName, token)
+		var applied *rewrite.Applied
+		m.Host, applied = rewriteHost(eng, m.Name, m.Host)
+		resp, err := cl.PostJSON("/machines", token, m)
+		recordAudit(cmd, cl, token, "POST", "/machines", m, resp, err)
 		if err != nil {
 			return err
 		}
-		fmt.Println("Deleted", mDelName)
-		return nil
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		r := newResult("create", "machine", m.Name, resp, nil)
+		r.HostRewrite = applied
+		return printResult(r)
 	},
 }
 
@@ -99,11 +110,15 @@ func init() {
 	machinesCmd.AddCommand(machinesAddCmd)
 	machinesCmd.AddCommand(machinesDelCmd)
 
+	addListFlags(machinesListCmd)
+	machinesListCmd.Flags().StringVarP(&listOpts.Labels, "label-selector", "l", "", "Label selector: os=alpine, os!=debian, 'os in (alpine,debian)', gpu, !cordoned")
+
 	machinesAddCmd.Flags().StringVar(&mAddName, "name", "", "Machine name")
-	machinesAddCmd.Flags().StringVar(&mAddHost, "host", "", "Machine host (rewritten if localhost/127.0.0.1)")
+	machinesAddCmd.Flags().StringVar(&mAddHost, "host", "", "Machine host (host_rewrites and the loopback rewrite apply)")
 	machinesAddCmd.Flags().IntVar(&mAddPort, "port", 22, "SSH port")
 	machinesAddCmd.Flags().StringVar(&mAddUser, "user", "root", "SSH user")
-	machinesAddCmd.Flags().StringVar(&mAddPassword, "password", "", "SSH password")
-
-	machinesDelCmd.Flags().StringVar(&mDelName, "name", "", "Machine name")
+	mAddPassword = addSecretFlags(machinesAddCmd, "password", "", "SSH password", true)
+	machinesAddCmd.Flags().StringVar(&mAddKeyFile, "key-file", "", "SSH private key file (sent instead of, or with, the password)")
+	mAddPass = addSecretFlags(machinesAddCmd, "passphrase", "", "passphrase of an encrypted --key-file", true)
+	machinesAddCmd.Flags().StringArrayVar(&mAddLabels, "label", nil, "Label key=value (repeatable)")
 }
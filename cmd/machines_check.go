@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/Jeomhps/projet-iac-cli/internal/hostgw"
+	"github.com/Jeomhps/projet-iac-cli/internal/labels"
+	"github.com/Jeomhps/projet-iac-cli/internal/listing"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
+	"github.com/Jeomhps/projet-iac-cli/internal/sshcheck"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mCheckAll      bool
+	mCheckLabels   string
+	mCheckParallel int
+	mCheckTimeout  time.Duration
+	mCheckPassword *secretFlags
+)
+
+var checkColumns = []output.Column{
+	output.Col("NAME", "name"),
+	output.Col("ADDRESS", "address"),
+	output.Col("REACHABLE", "reachable"),
+	output.Col("AUTH", "auth_ok"),
+	output.Col("LATENCY", "latency"),
+	output.Col("ERROR", "error"),
+	output.WideCol("HOST KEY", "host_key"),
+	output.WideCol("KEY TYPE", "key_type"),
+}
+
+var machinesCheckCmd = &cobra.Command{
+	Use:   "check [name...]",
+	Short: "Check SSH connectivity and credentials of machines (admin)",
+	Long: "Connect to each machine's host:port, perform an SSH handshake and a password\n" +
+		"or key login with the registered credentials, and report reachability, login, host key\n" +
+		"fingerprint and latency. Machines registered with the Docker host name, the\n" +
+		"detected Docker/Podman gateway or a host_rewrites target for localhost are\n" +
+		"dialled on localhost, as seen from this host. When the API does not return\n" +
+		"passwords, give one with --password/--password-stdin/...",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && !mCheckAll && mCheckLabels == "" {
+			return fmt.Errorf("give machine names, --label-selector or --all")
+		}
+		if mCheckParallel <= 0 {
+			return fmt.Errorf("--parallel must be > 0")
+		}
+		sel, err := labels.Parse(mCheckLabels)
+		if err != nil {
+			return err
+		}
+		fallback, err := mCheckPassword.resolve("SSH password", false, false)
+		if err != nil {
+			return err
+		}
+
+		cl := client.New(cfg)
+		token, err := cl.GetToken()
+		if err != nil {
+			return err
+		}
+		resp, err := cl.Get("/machines", token)
+		if err != nil {
+			return err
+		}
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		var items []map[string]any
+		if err := json.Unmarshal(resp.Body, &items); err != nil {
+			return fmt.Errorf("decode machines: %w", err)
+		}
+
+		wanted := map[string]bool{}
+		for _, n := range args {
+			wanted[n] = true
+		}
+		local := localAliases()
+		var targets []sshcheck.Target
+		for _, it := range items {
+			name, _ := it["name"].(string)
+			switch {
+			case wanted[name]:
+				delete(wanted, name)
+			case len(args) > 0:
+				continue
+			case !mCheckAll && !listing.MatchLabels(it, sel):
+				continue
+			}
+			targets = append(targets, checkTarget(it, fallback, local))
+		}
+		for _, n := range uniqueSorted(keys(wanted)) {
+			fmt.Fprintf(os.Stderr, "Warning: machine %q not found\n", n)
+		}
+		if len(targets) == 0 {
+			fmt.Fprintln(os.Stderr, "No machines matched.")
+		}
+
+		checker := sshcheck.Checker{Timeout: mCheckTimeout}
+		results := checker.CheckAll(cmd.Context(), targets, mCheckParallel)
+		failed := 0
+		for i, r := range results {
+			if !r.OK() {
+				failed++
+			}
+			if r.Reachable && !r.AuthOK && targets[i].Password == "" && targets[i].PrivateKey == "" {
+				results[i].Error = "no password known (the API does not return it; use --password)"
+			}
+		}
+
+		b, err := json.Marshal(results)
+		if err != nil {
+			return err
+		}
+		if err := printBody(b, checkColumns); err != nil {
+			return err
+		}
+		if failed > 0 || len(wanted) > 0 {
+			return fmt.Errorf("%d of %d machines failed the check", failed+len(wanted), len(results)+len(wanted))
+		}
+		return nil
+	},
+}
+
+// checkTarget turns a machine from the API into an SSH target. The API stores
+// addresses as the containers see them, so hosts in local are dialled as
+// localhost from here.
+func checkTarget(m map[string]any, fallback string, local map[string]bool) sshcheck.Target {
+	t := sshcheck.Target{Password: fallback}
+	t.Name, _ = m["name"].(string)
+	t.Host, _ = m["host"].(string)
+	t.User, _ = m["user"].(string)
+	if p, ok := m["port"].(float64); ok {
+		t.Port = int(p)
+	}
+	if p, ok := m["password"].(string); ok && p != "" {
+		t.Password = p
+	}
+	t.PrivateKey, _ = m["private_key"].(string)
+	t.Passphrase, _ = m["passphrase"].(string)
+	if local[strings.ToLower(t.Host)] {
+		t.Host = "localhost"
+	}
+	return t
+}
+
+// localAliases returns the lower-cased hosts that stand for this machine in
+// the API: what the configured rewrites turn loopback addresses into, the
+// Docker host name, and the detected Docker/Podman gateway.
+func localAliases() map[string]bool {
+	local := map[string]bool{}
+	add := func(h string) {
+		if h = strings.TrimSpace(h); h != "" {
+			local[strings.ToLower(h)] = true
+		}
+	}
+	add(cfg.DockerHostGatewayName)
+	if eng, err := cfg.HostRewriter(); err == nil {
+		for _, h := range []string{"localhost", "127.0.0.1", "::1", "0.0.0.0", "::"} {
+			if to, applied := eng.Rewrite(h); applied != nil {
+				add(to)
+			}
+		}
+	}
+	det := hostgw.Detector{}.Detect()
+	add(det.Target)
+	add(det.Bridge)
+	return local
+}
+
+func keys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+func init() {
+	machinesCheckCmd.Flags().BoolVar(&mCheckAll, "all", false, "Check every machine")
+	machinesCheckCmd.Flags().StringVarP(&mCheckLabels, "label-selector", "l", "", "Check machines whose labels match this selector")
+	machinesCheckCmd.Flags().IntVar(&mCheckParallel, "parallel", 8, "Number of concurrent checks")
+	machinesCheckCmd.Flags().DurationVar(&mCheckTimeout, "timeout", 10*time.Second, "Per-machine timeout")
+	mCheckPassword = addSecretFlags(machinesCheckCmd, "password", "", "SSH password to use when the API does not return one", true)
+	machinesCmd.AddCommand(machinesCheckCmd)
+}
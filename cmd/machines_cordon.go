@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/Jeomhps/projet-iac-cli/internal/fleet"
+	"github.com/Jeomhps/projet-iac-cli/internal/labels"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mDrainTimeout  time.Duration
+	mDrainInterval time.Duration
+)
+
+const cordonLong = "Cordoned machines are kept out of new reservations. Servers with a\n" +
+	"cordoned field on machines get it set; otherwise the machine is labelled\n" +
+	"cordoned=true, and reserve then sends a label selector excluding it."
+
+var machinesCordonCmd = &cobra.Command{
+	Use:   "cordon <name>",
+	Short: "Stop a machine from being reserved (admin)",
+	Long:  cordonLong,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := runCordon(cmd, args[0], true)
+		if err != nil {
+			return err
+		}
+		return printResult(r)
+	},
+}
+
+var machinesUncordonCmd = &cobra.Command{
+	Use:   "uncordon <name>",
+	Short: "Let a cordoned machine be reserved again (admin)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := runCordon(cmd, args[0], false)
+		if err != nil {
+			return err
+		}
+		return printResult(r)
+	},
+}
+
+var machinesDrainCmd = &cobra.Command{
+	Use:   "drain <name>",
+	Short: "Cordon a machine and wait for its reservation to end (admin)",
+	Long: "Cordon the machine, then poll it until its current reservation expires or\n" +
+		"is released. The machine stays cordoned afterwards, ready for maintenance;\n" +
+		"run 'machines uncordon' when done.\n\n" + cordonLong,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if mDrainInterval <= 0 {
+			return fmt.Errorf("--interval must be > 0")
+		}
+		if _, err := runCordon(cmd, name, true); err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if mDrainTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, mDrainTimeout)
+			defer cancel()
+		}
+
+		cl := client.New(cfg)
+		var waitingFor string
+		for {
+			m, state, err := machineState(cl, name)
+			if err != nil {
+				return err
+			}
+			if !state.Reserved {
+				body, err := json.Marshal(m)
+				if err != nil {
+					return err
+				}
+				return printResult(result{Action: "drain", Resource: "machine", Name: name, Status: statusOK, Response: body})
+			}
+			if msg := drainWaitMessage(name, state); msg != waitingFor {
+				fmt.Fprintln(os.Stderr, msg)
+				waitingFor = msg
+			}
+
+			select {
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded {
+					return fmt.Errorf("%s still reserved after %s; it stays cordoned", name, mDrainTimeout)
+				}
+				return fmt.Errorf("interrupted; %s stays cordoned", name)
+			case <-time.After(mDrainInterval):
+			}
+		}
+	},
+}
+
+// runCordon cordons or uncordons a machine, through the server's cordoned
+// field when the machine has one and the cordoned label otherwise. A machine
+// already in the wanted state gives a skipped result.
+func runCordon(cmd *cobra.Command, name string, on bool) (result, error) {
+	action := "cordon"
+	if !on {
+		action = "uncordon"
+	}
+	cl := client.New(cfg)
+	token, err := cl.GetToken()
+	if err != nil {
+		return result{}, err
+	}
+	m, err := getMachine(cl, token, name)
+	if err != nil {
+		return result{}, err
+	}
+	if fleet.Cordoned(m) == on {
+		state := "already cordoned"
+		if !on {
+			state = "not cordoned"
+		}
+		return result{Action: action, Resource: "machine", Name: name, Status: statusSkipped, Error: state}, nil
+	}
+
+	var payload map[string]any
+	if _, ok := m["cordoned"].(bool); ok {
+		payload = map[string]any{"cordoned": on}
+	} else {
+		current := labels.FromAny(m["labels"])
+		if current == nil {
+			current = map[string]string{}
+		}
+		if on {
+			current[fleet.CordonLabel] = "true"
+		} else {
+			delete(current, fleet.CordonLabel)
+		}
+		payload = map[string]any{"labels": current}
+		if on {
+			fmt.Fprintf(os.Stderr, "%s: no cordoned field on the server, using the %s=true label; reserve excludes it with its label selector, but other clients and servers ignoring label_selector can still hand it out\n", name, fleet.CordonLabel)
+		}
+	}
+
+	path := machinePath(name)
+	resp, err := cl.PatchJSON(path, token, payload)
+	recordAudit(cmd, cl, token, "PATCH", path, payload, resp, err)
+	if err != nil {
+		return result{}, err
+	}
+	if err := resp.Err(); err != nil {
+		return result{}, err
+	}
+	return newResult(action, "machine", name, resp, nil), nil
+}
+
+// machineState fetches a machine and its reservation state. Reservations
+// refine the machine's own fields when the list is available.
+func machineState(cl *client.Client, name string) (map[string]any, fleet.Machine, error) {
+	token, err := cl.GetToken()
+	if err != nil {
+		return nil, fleet.Machine{}, err
+	}
+	m, err := getMachine(cl, token, name)
+	if err != nil {
+		return nil, fleet.Machine{}, err
+	}
+	reservations, _ := getList(cl, "/reservations")
+	joined := fleet.Join([]any{m}, reservations, time.Now())
+	if len(joined) == 0 {
+		return m, fleet.Machine{Name: name}, nil
+	}
+	return m, joined[0], nil
+}
+
+func drainWaitMessage(name string, state fleet.Machine) string {
+	who := "the current reservation"
+	if state.User != "" {
+		who = state.User + "'s reservation"
+	}
+	msg := fmt.Sprintf("Waiting for %s on %s to end", who, name)
+	if !state.Until.IsZero() {
+		if left := time.Until(state.Until); left > 0 {
+			msg += fmt.Sprintf(" (expires %s, in %s)", state.Until.Local().Format(time.DateTime), output.HumanDuration(left.Round(time.Minute)))
+		} else {
+			msg += " (expiry passed; waiting for the server to release it)"
+		}
+	}
+	return msg + "..."
+}
+
+func init() {
+	machinesCmd.AddCommand(machinesCordonCmd)
+	machinesCmd.AddCommand(machinesUncordonCmd)
+	machinesCmd.AddCommand(machinesDrainCmd)
+
+	machinesDrainCmd.Flags().DurationVar(&mDrainTimeout, "timeout", time.Hour, "Give up after this long (0 waits indefinitely)")
+	machinesDrainCmd.Flags().DurationVar(&mDrainInterval, "interval", 10*time.Second, "Polling interval")
+}
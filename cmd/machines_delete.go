@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/Jeomhps/projet-iac-cli/internal/labels"
+	"github.com/Jeomhps/projet-iac-cli/internal/listing"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mDelName      string
+	mDelSelectors []string
+	mDelLabels    string
+	mDelHost      string
+	mDelAll       bool
+	mDelYes       bool
+	mDelParallel  int
+)
+
+var machinesDelCmd = &cobra.Command{
+	Use:   "delete [name...]",
+	Short: "Delete machines by name, selector, host or --all (admin)",
+	Long: "Delete one or more machines. Machines can be named as arguments (or --name),\n" +
+		"or matched with --selector (same syntax as --filter, e.g. 'name=alpine-*'),\n" +
+		"--host, or --all. Deleting more than one machine, or deleting by selector,\n" +
+		"shows the matched set and asks for confirmation unless --yes is given.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names := append([]string{}, args...)
+		if mDelName != "" {
+			names = append(names, mDelName)
+		}
+		bySelector := len(mDelSelectors) > 0 || mDelLabels != "" || mDelHost != "" || mDelAll
+		if len(names) == 0 && !bySelector {
+			return fmt.Errorf("give machine names, --selector, --label-selector, --host or --all")
+		}
+		if mDelParallel <= 0 {
+			return fmt.Errorf("--parallel must be > 0")
+		}
+
+		cl := client.New(cfg)
+		token, err := cl.GetToken()
+		if err != nil {
+			return err
+		}
+
+		if bySelector {
+			matched, err := selectMachines(cl, token)
+			if err != nil {
+				return err
+			}
+			names = append(names, matched...)
+		}
+		names = uniqueSorted(names)
+		if len(names) == 0 {
+			fmt.Fprintln(os.Stderr, "No machines matched.")
+			return printResults(nil)
+		}
+
+		if bySelector || len(names) > 1 {
+			fmt.Fprintf(os.Stderr, "The following %d machine(s) will be deleted:\n", len(names))
+			for _, n := range names {
+				fmt.Fprintln(os.Stderr, "  "+n)
+			}
+			if err := confirm(fmt.Sprintf("Delete %d machine(s)?", len(names)), mDelYes); err != nil {
+				return err
+			}
+		}
+
+		results := make([]result, len(names))
+		errs := make([]error, len(names))
+		sem := make(chan struct{}, mDelParallel)
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				path := machinePath(name)
+				resp, err := cl.Delete(path, token)
+				recordAudit(cmd, cl, token, "DELETE", path, nil, resp, err)
+				results[i] = newResult("delete", "machine", name, resp, err)
+				if err == nil && resp != nil {
+					err = resp.Err()
+				}
+				errs[i] = err
+			}(i, name)
+		}
+		wg.Wait()
+
+		if err := printResults(results); err != nil {
+			return err
+		}
+		failed := 0
+		for _, r := range results {
+			if r.Status != statusOK {
+				failed++
+			}
+		}
+		if failed > 0 && len(results) == 1 && errs[0] != nil {
+			return errs[0] // keeps the API error code for structured output
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d deletions failed", failed, len(results))
+		}
+		return nil
+	},
+}
+
+// selectMachines lists machines and returns the names matched by --all,
+// --host and --selector.
+func selectMachines(cl *client.Client, token string) ([]string, error) {
+	resp, err := cl.Get("/machines", token)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	var items []any
+	if err := json.Unmarshal(resp.Body, &items); err != nil {
+		return nil, fmt.Errorf("decode machines: %w", err)
+	}
+
+	filters := make([]listing.Filter, 0, len(mDelSelectors)+1)
+	for _, s := range mDelSelectors {
+		f, err := listing.ParseFilter(s)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	if mDelHost != "" {
+		filters = append(filters, listing.Filter{Key: "host", Op: "=", Value: mDelHost})
+	}
+	sel, err := labels.Parse(mDelLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, it := range items {
+		if !mDelAll && len(filters) == 0 && len(sel) == 0 {
+			break
+		}
+		if !listing.MatchAll(it, filters) || !listing.MatchLabels(it, sel) {
+			continue
+		}
+		if m, ok := it.(map[string]any); ok {
+			if n, ok := m["name"].(string); ok && n != "" {
+				names = append(names, n)
+			}
+		}
+	}
+	return names, nil
+}
+
+func uniqueSorted(in []string) []string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if s != "" && !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func init() {
+	machinesDelCmd.Flags().StringVar(&mDelName, "name", "", "Machine name")
+	machinesDelCmd.Flags().StringArrayVar(&mDelSelectors, "selector", nil, "Delete machines matching key=value/glob expressions (repeatable, ANDed), e.g. 'name=alpine-*'")
+	machinesDelCmd.Flags().StringVarP(&mDelLabels, "label-selector", "l", "", "Delete machines whose labels match this selector (e.g. os=alpine)")
+	machinesDelCmd.Flags().StringVar(&mDelHost, "host", "", "Delete machines with this host")
+	machinesDelCmd.Flags().BoolVar(&mDelAll, "all", false, "Delete every machine")
+	machinesDelCmd.Flags().BoolVarP(&mDelYes, "yes", "y", false, "Do not ask for confirmation")
+	machinesDelCmd.Flags().IntVar(&mDelParallel, "parallel", 8, "Number of concurrent deletions")
+}
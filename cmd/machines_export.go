@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/Jeomhps/projet-iac-cli/internal/labels"
+	"github.com/Jeomhps/projet-iac-cli/internal/types"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// redactedSecret replaces passwords and keys in exported files. register
+// treats it like a missing value and falls back to --password.
+const redactedSecret = "<redacted>"
+
+var (
+	mExportFile   string
+	mExportRedact bool
+)
+
+// machinesFile is the layout register reads.
+type machinesFile struct {
+	Machines []types.MachineCreate `yaml:"machines"`
+}
+
+var machinesExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export machines as a YAML file that register accepts (admin)",
+	Long: "Write the current machines as a top-level 'machines:' list, the format of\n" +
+		"'register -f'. Passwords and keys are included when the API returns them; with\n" +
+		"--redact-secrets they are replaced by \"" + redactedSecret + "\", which register\n" +
+		"treats as missing (passwords then come from --password).",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cl := client.New(cfg)
+		token, err := cl.GetToken()
+		if err != nil {
+			return err
+		}
+		resp, err := cl.Get("/machines", token)
+		if err != nil {
+			return err
+		}
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		var items []map[string]any
+		if err := json.Unmarshal(resp.Body, &items); err != nil {
+			return fmt.Errorf("decode machines: %w", err)
+		}
+		machines := make([]types.MachineCreate, len(items))
+		for i, it := range items {
+			machines[i] = exportedMachine(it)
+		}
+		sort.Slice(machines, func(i, j int) bool { return machines[i].Name < machines[j].Name })
+
+		noPassword := 0
+		for i := range machines {
+			m := &machines[i]
+			if m.Password == "" && m.PrivateKey == "" {
+				noPassword++
+			}
+			if mExportRedact {
+				redact(&m.Password)
+				redact(&m.PrivateKey)
+				redact(&m.Passphrase)
+			}
+		}
+		if noPassword > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: the API returned no password or key for %d machine(s); register will need --password for them.\n", noPassword)
+		}
+
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(machinesFile{Machines: machines}); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+
+		if mExportFile == "" || mExportFile == "-" {
+			_, err := resultWriter().Write(buf.Bytes())
+			return err
+		}
+		// Passwords may be in the file: keep it private to the user.
+		if err := writeFileAtomic(mExportFile, buf.Bytes(), 0o600); err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d machine(s) to %s\n", len(machines), mExportFile)
+		return nil
+	},
+}
+
+// exportedMachine turns a machine from the API into a register entry. Labels
+// are converted with labels.FromAny, so numbers and booleans survive.
+func exportedMachine(m map[string]any) types.MachineCreate {
+	var mc types.MachineCreate
+	mc.Name, _ = m["name"].(string)
+	mc.Host, _ = m["host"].(string)
+	mc.User, _ = m["user"].(string)
+	if p, ok := m["port"].(float64); ok {
+		mc.Port = int(p)
+	}
+	mc.Password, _ = m["password"].(string)
+	mc.PrivateKey, _ = m["private_key"].(string)
+	mc.Passphrase, _ = m["passphrase"].(string)
+	if l := labels.FromAny(m["labels"]); len(l) > 0 {
+		mc.Labels = l
+	}
+	return mc
+}
+
+// redact replaces a secret with redactedSecret; empty ones stay empty, so
+// the file does not claim secrets the machine never had.
+func redact(s *string) {
+	if *s != "" {
+		*s = redactedSecret
+	}
+}
+
+func init() {
+	machinesExportCmd.Flags().StringVarP(&mExportFile, "file", "f", "", "Write to this file instead of stdout")
+	machinesExportCmd.Flags().BoolVar(&mExportRedact, "redact-secrets", false, "Replace passwords and keys with \""+redactedSecret+"\"")
+	machinesCmd.AddCommand(machinesExportCmd)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/Jeomhps/projet-iac-cli/internal/labels"
+	"github.com/spf13/cobra"
+)
+
+var machinesLabelCmd = &cobra.Command{
+	Use:   "label <name> key=value... [key-...]",
+	Short: "Set or remove labels on a machine (admin)",
+	Long:  "Set labels with key=value and remove them with key- (e.g. 'machines label alpine-1 os=alpine gpu-').",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		set, remove, err := labels.ParseAssignments(args[1:], true)
+		if err != nil {
+			return err
+		}
+		cl := client.New(cfg)
+		token, err := cl.GetToken()
+		if err != nil {
+			return err
+		}
+
+		current, err := machineLabels(cl, token, name)
+		if err != nil {
+			return err
+		}
+		for k, v := range set {
+			current[k] = v
+		}
+		for _, k := range remove {
+			delete(current, k)
+		}
+
+		path := machinePath(name)
+		payload := map[string]any{"labels": current}
+		resp, err := cl.PatchJSON(path, token, payload)
+		recordAudit(cmd, cl, token, "PATCH", path, payload, resp, err)
+		if err != nil {
+			return err
+		}
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		return printResult(newResult("label", "machine", name, resp, nil))
+	},
+}
+
+// machineLabels fetches a machine's current labels (never nil).
+func machineLabels(cl *client.Client, token, name string) (map[string]string, error) {
+	m, err := getMachine(cl, token, name)
+	if err != nil {
+		return nil, err
+	}
+	current := labels.FromAny(m["labels"])
+	if current == nil {
+		current = map[string]string{}
+	}
+	return current, nil
+}
+
+// getMachine fetches one machine as a generic object.
+func getMachine(cl *client.Client, token, name string) (map[string]any, error) {
+	resp, err := cl.Get(machinePath(name), token)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(resp.Body, &m); err != nil {
+		return nil, fmt.Errorf("decode machine %s: %w", name, err)
+	}
+	return m, nil
+}
+
+func init() {
+	machinesCmd.AddCommand(machinesLabelCmd)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/Jeomhps/projet-iac-cli/internal/fleet"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mStatsHours float64
+	mStatsLabel string
+)
+
+// Columns of the three tables machines stats prints.
+var (
+	statsCountColumns = []output.Column{
+		output.Col("MACHINES", "scope"),
+		output.Col("TOTAL", "total"),
+		output.Col("FREE", "free"),
+		output.Col("RESERVED", "reserved"),
+		output.Col("CORDONED", "cordoned"),
+	}
+	statsUserColumns = []output.Column{
+		output.Col("USER", "user"),
+		output.Col("HOLDS", "count"),
+		output.Col("NEXT RELEASE", "next_release"),
+		output.WideCol("MACHINES", "machines"),
+	}
+	statsTimelineColumns = []output.Column{
+		output.Col("RELEASE AT", "at"),
+		output.Col("COUNT", "count"),
+		output.Col("FREE AFTER", "free_after"),
+		output.Col("MACHINES", "machines"),
+	}
+)
+
+var machinesStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarise free, reserved and cordoned machines, holdings and upcoming releases",
+	Long: "Join /machines and /reservations to report total/free/reserved/cordoned\n" +
+		"counts (per value of a label with --by-label), machines held per user, and\n" +
+		"when reserved machines free up over the next --hours.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if mStatsHours <= 0 {
+			return fmt.Errorf("--hours must be > 0")
+		}
+		cl := client.New(cfg)
+		machines, err := getList(cl, "/machines")
+		if err != nil {
+			return err
+		}
+		// Reservations refine the machine fields but are not essential.
+		reservations, err := getList(cl, "/reservations")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: reservations unavailable (%v); using machine fields only\n", err)
+		}
+
+		window := time.Duration(mStatsHours * float64(time.Hour))
+		now := time.Now()
+		sum := fleet.Summarize(fleet.Join(machines, reservations, now), now, window, mStatsLabel)
+
+		format := output.ResolveFormat(outputFormat)
+		if (format != output.Table && format != output.Wide) || flagQuery != "" {
+			b, err := json.Marshal(sum)
+			if err != nil {
+				return err
+			}
+			return printBody(b, nil)
+		}
+
+		w := resultWriter()
+		sections := []struct {
+			title string
+			rows  any
+			cols  []output.Column
+		}{
+			{"", sum.Counts, statsCountColumns},
+			{"", sum.Users, statsUserColumns},
+			{fmt.Sprintf("Releases in the next %s:", output.HumanDuration(window)), sum.Timeline, statsTimelineColumns},
+		}
+		for i, sec := range sections {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if sec.title != "" {
+				fmt.Fprintln(w, sec.title)
+			}
+			b, err := json.Marshal(sec.rows)
+			if err != nil {
+				return err
+			}
+			if string(b) == "[]" {
+				fmt.Fprintln(w, "(none)")
+				continue
+			}
+			if err := printSelected(b, sec.cols, nil); err != nil {
+				return err
+			}
+		}
+		if sum.NotInWindow > 0 {
+			fmt.Fprintf(w, "%d reserved machine(s) free up later, are overdue, or have no end time.\n", sum.NotInWindow)
+		}
+		return nil
+	},
+}
+
+func init() {
+	machinesStatsCmd.Flags().Float64Var(&mStatsHours, "hours", 24, "Timeline window in hours")
+	machinesStatsCmd.Flags().StringVar(&mStatsLabel, "by-label", "", "Also count per value of this label key (e.g. os)")
+	machinesCmd.AddCommand(machinesStatsCmd)
+}
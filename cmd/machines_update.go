@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/Jeomhps/projet-iac-cli/internal/rewrite"
+	"github.com/Jeomhps/projet-iac-cli/internal/sshkey"
+	"github.com/Jeomhps/projet-iac-cli/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var machinesGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Show one machine",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cl := client.New(cfg)
+		token, err := cl.GetToken()
+		if err != nil {
+			return err
+		}
+		resp, err := cl.Get(machinePath(args[0]), token)
+		if err != nil {
+			return err
+		}
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		return printBody(resp.Body, machineColumns)
+	},
+}
+
+var (
+	mUpdHost     string
+	mUpdPort     int
+	mUpdUser     string
+	mUpdPassword *secretFlags
+	mUpdKeyFile  string
+	mUpdPass     *secretFlags
+)
+
+var machinesUpdateCmd = &cobra.Command{
+	Use:   "update <name>",
+	Short: "Update a machine's host, port, user, password or key (admin)",
+	Long: "Update a machine in place, keeping its reservations. Only the given fields change.\n" +
+		"Uses PATCH; servers without PATCH support get a PUT of the machine as the server\n" +
+		"returns it with the changes applied, which needs --password or --key-file since\n" +
+		"the API does not return credentials. Both requests are audited.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		cl := client.New(cfg)
+
+		var (
+			upd     types.MachineUpdate
+			applied *rewrite.Applied
+		)
+		if cmd.Flags().Changed("host") {
+			eng, err := cfg.HostRewriter()
+			if err != nil {
+				return err
+			}
+			var h string
+			h, applied = rewriteHost(eng, name, mUpdHost)
+			upd.Host = &h
+		}
+		if cmd.Flags().Changed("port") {
+			if mUpdPort <= 0 {
+				return fmt.Errorf("--port must be > 0")
+			}
+			upd.Port = &mUpdPort
+		}
+		if cmd.Flags().Changed("user") {
+			upd.User = &mUpdUser
+		}
+		if mUpdPassword.provided() {
+			p, err := mUpdPassword.resolve("SSH password", false, true)
+			if err != nil {
+				return err
+			}
+			upd.Password = &p
+		}
+		if mUpdKeyFile != "" {
+			m := types.MachineCreate{Name: name, PrivateKeyFile: mUpdKeyFile}
+			if mUpdPass.provided() {
+				p, err := mUpdPass.resolve("SSH key passphrase", false, true)
+				if err != nil {
+					return err
+				}
+				m.Passphrase = p
+			}
+			err := loadMachineKey(&m, "")
+			if errors.Is(err, sshkey.ErrPassphraseNeeded) && !mUpdPass.provided() {
+				if m.Passphrase, err = mUpdPass.resolve("SSH key passphrase", false, true); err == nil {
+					err = loadMachineKey(&m, "")
+				}
+			}
+			if err != nil {
+				return err
+			}
+			upd.PrivateKey = &m.PrivateKey
+			if m.Passphrase != "" {
+				upd.Passphrase = &m.Passphrase
+			}
+		}
+		if upd == (types.MachineUpdate{}) {
+			return fmt.Errorf("nothing to update: give at least one of --host --port --user --password --key-file")
+		}
+
+		token, err := cl.GetToken()
+		if err != nil {
+			return err
+		}
+		path := machinePath(name)
+		resp, err := cl.PatchJSON(path, token, upd)
+		recordAudit(cmd, cl, token, http.MethodPatch, path, upd, resp, err)
+		if err == nil && resp.StatusCode == http.StatusMethodNotAllowed {
+			// A PUT replaces the credentials too, and the API does not return
+			// them: only fall back when the user is setting them anyway.
+			if upd.Password == nil && upd.PrivateKey == nil {
+				return fmt.Errorf("the server does not support PATCH and a PUT would reset the SSH credentials of %s; pass --password or --key-file to update it", name)
+			}
+			full, ferr := mergedMachine(cl, token, name, upd)
+			if ferr != nil {
+				return ferr
+			}
+			resp, err = cl.PutJSON(path, token, full)
+			recordAudit(cmd, cl, token, http.MethodPut, path, full, resp, err)
+		}
+		if err != nil {
+			return err
+		}
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		r := newResult("update", "machine", name, resp, nil)
+		r.HostRewrite = applied
+		return printResult(r)
+	},
+}
+
+// mergedMachine fetches a machine and applies upd on top, for servers that
+// only support full replacement. The whole fetched object is the base, so
+// fields the CLI does not model (cordoned, labels of any type, ...) are
+// sent back unchanged. Secret fields come from upd only, never from the
+// fetched machine.
+func mergedMachine(cl *client.Client, token, name string, upd types.MachineUpdate) (map[string]any, error) {
+	m, err := getMachine(cl, token, name)
+	if err != nil {
+		return nil, err
+	}
+	delete(m, "password")
+	delete(m, "private_key")
+	delete(m, "passphrase")
+	if upd.Host != nil {
+		m["host"] = *upd.Host
+	}
+	if upd.Port != nil {
+		m["port"] = *upd.Port
+	}
+	if upd.User != nil {
+		m["user"] = *upd.User
+	}
+	if upd.Password != nil {
+		m["password"] = *upd.Password
+	}
+	if upd.PrivateKey != nil {
+		m["private_key"] = *upd.PrivateKey
+	}
+	if upd.Passphrase != nil {
+		m["passphrase"] = *upd.Passphrase
+	}
+	return m, nil
+}
+
+// machinePath returns the API path of a machine.
+func machinePath(name string) string {
+	return "/machines/" + url.PathEscape(name)
+}
+
+func init() {
+	machinesCmd.AddCommand(machinesGetCmd)
+	machinesCmd.AddCommand(machinesUpdateCmd)
+
+	machinesUpdateCmd.Flags().StringVar(&mUpdHost, "host", "", "New host (host_rewrites and the loopback rewrite apply)")
+	machinesUpdateCmd.Flags().IntVar(&mUpdPort, "port", 0, "New SSH port")
+	machinesUpdateCmd.Flags().StringVar(&mUpdUser, "user", "", "New SSH user")
+	mUpdPassword = addSecretFlags(machinesUpdateCmd, "password", "", "new SSH password", true)
+	machinesUpdateCmd.Flags().StringVar(&mUpdKeyFile, "key-file", "", "New SSH private key file")
+	mUpdPass = addSecretFlags(machinesUpdateCmd, "passphrase", "", "passphrase of an encrypted --key-file", true)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var (
+	flagOutputFile string
+	// resultBuf collects results while --output-file is set
+	resultBuf bytes.Buffer
+)
+
+// resultWriter is where command results go: stdout, or a buffer that is
+// written to --output-file once the command finishes. Human-oriented messages
+// keep using the terminal directly.
+func resultWriter() io.Writer {
+	if flagOutputFile != "" {
+		return &resultBuf
+	}
+	return os.Stdout
+}
+
+// flushOutputFile atomically writes collected results to --output-file.
+func flushOutputFile() error {
+	if flagOutputFile == "" {
+		return nil
+	}
+	if err := writeFileAtomic(flagOutputFile, resultBuf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("output file: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory, then
+// renames it over path, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
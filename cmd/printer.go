@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/listing"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// Table columns per resource (wide-only columns appear with -o wide).
+var (
+	machineColumns = []output.Column{
+		output.Col("NAME", "name"),
+		output.Col("HOST", "host"),
+		output.Col("PORT", "port"),
+		output.Col("USER", "user"),
+		output.Col("RESERVED BY", "reserved_by", "reservation.username", "reservation.user"),
+		output.Col("UNTIL", "reserved_until", "reservation.expires_at", "expires_at"),
+		output.Col("CORDONED", "cordoned", "labels.cordoned"),
+		output.WideCol("ID", "id"),
+		output.WideCol("RESERVED", "reserved"),
+		output.WideCol("LABELS", "labels"),
+		output.WideCol("CREATED", "created_at"),
+	}
+	reservationColumns = []output.Column{
+		output.Col("ID", "id"),
+		output.Col("USER", "username", "user"),
+		output.Col("MACHINES", "machines", "machine_names", "machine", "machine_name"),
+		output.Col("EXPIRES", "expires_at", "reserved_until", "until"),
+		output.WideCol("HOSTS", "machines.host", "host"),
+		output.WideCol("CREATED", "created_at", "reserved_at"),
+	}
+	userColumns = []output.Column{
+		output.Col("USERNAME", "username"),
+		output.Col("ADMIN", "is_admin", "admin"),
+		output.WideCol("ID", "id"),
+		output.WideCol("CREATED", "created_at"),
+	}
+)
+
+// printBody renders an API response body on stdout in the selected format.
+// cols may be nil to derive columns from the data.
+func printBody(body []byte, cols []output.Column) error {
+	return printSelected(body, cols, flagColumns)
+}
+
+// printSelected is printBody with an explicit column selection.
+func printSelected(body []byte, cols []output.Column, sel []string) error {
+	return output.Print(resultWriter(), body, output.Options{
+		Format:     outputFormat,
+		Color:      colorMode,
+		NoHeaders:  flagNoHeaders,
+		Columns:    cols,
+		Select:     sel,
+		Query:      flagQuery,
+		QueryFirst: flagQueryFirst,
+	})
+}
+
+// listOpts holds the --filter/--sort-by/--reverse/--fields flags of whichever
+// list command is running.
+var listOpts listing.Options
+
+// addListFlags registers the shared list-processing flags on a list command.
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&listOpts.Filters, "filter", nil, "Filter items: key=value, key!=value, key>N, key<=N; globs like name=alpine-* (repeatable, ANDed)")
+	cmd.Flags().StringVar(&listOpts.SortBy, "sort-by", "", "Sort by field (e.g. port, reservation.expires_at)")
+	cmd.Flags().BoolVar(&listOpts.Reverse, "reverse", false, "Reverse the order")
+	cmd.Flags().StringSliceVar(&listOpts.Fields, "fields", nil, "Only keep these fields (e.g. name,host,port)")
+	cmd.Flags().BoolVarP(&listWatch, "watch", "w", false, "Keep polling and show changes (Ctrl-C to stop)")
+	cmd.Flags().DurationVar(&listInterval, "interval", 5*time.Second, "Polling interval for --watch")
+}
+
+// printList applies the list flags to a list response, then prints it.
+func printList(body []byte, cols []output.Column) error {
+	b, err := listing.Apply(body, listOpts)
+	if err != nil {
+		return err
+	}
+	sel := flagColumns
+	if len(sel) == 0 {
+		sel = listOpts.Fields
+	}
+	return printSelected(b, cols, sel)
+}
+
+// canStream reports whether a list response can be pretty-printed straight
+// from the network: pretty JSON output with no client-side processing.
+func canStream() bool {
+	return output.ResolveFormat(outputFormat) == output.Pretty &&
+		flagQuery == "" && len(flagColumns) == 0 && !listOpts.Active()
+}
@@ -1,53 +1,131 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/Jeomhps/projet-iac-cli/internal/inventory"
+	"github.com/Jeomhps/projet-iac-cli/internal/rewrite"
+	"github.com/Jeomhps/projet-iac-cli/internal/sshkey"
 	"github.com/Jeomhps/projet-iac-cli/internal/types"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
 
-var regFile string
+var (
+	regFile     string
+	regFormat   string
+	regCompose  string
+	regServices string
+	regSSHPort  int
+	regSSHUser  string
+	regPassword *secretFlags
+)
 
 var registerCmd = &cobra.Command{
 	Use:   "register",
-	Short: "Register machines from a YAML file (admin)",
+	Short: "Register machines from a YAML file, inventory or compose file (admin)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if regFile == "" {
-			return fmt.Errorf("--file is required")
-		}
-		data, err := os.ReadFile(regFile)
-		if err != nil {
-			return err
-		}
-		var parsed any
-		if err := yaml.Unmarshal(data, &parsed); err != nil {
-			return fmt.Errorf("parse YAML: %w", err)
+		var (
+			machines []types.MachineCreate
+			skipped  []inventory.Skipped
+		)
+		switch {
+		case regFile != "" && regCompose != "":
+			return fmt.Errorf("use either --file or --from-compose")
+		case regCompose != "":
+			data, err := os.ReadFile(regCompose)
+			if err != nil {
+				return err
+			}
+			machines, skipped, err = inventory.ParseCompose(data, inventory.ComposeOptions{
+				ServicePattern: regServices,
+				SSHPort:        regSSHPort,
+				User:           regSSHUser,
+			})
+			if err != nil {
+				return err
+			}
+		case regFile != "":
+			data, err := os.ReadFile(regFile)
+			if err != nil {
+				return err
+			}
+			machines, skipped, err = parseMachinesFile(regFormat, data)
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("--file or --from-compose is required")
 		}
 
-		var machines []types.MachineCreate
-		switch t := parsed.(type) {
-		case map[string]any:
-			if m, ok := t["machines"]; ok {
-				b, _ := json.Marshal(m)
-				_ = json.Unmarshal(b, &machines)
+		// Redacted exports carry a placeholder instead of the secrets.
+		for i := range machines {
+			m := &machines[i]
+			if m.Password == redactedSecret {
+				m.Password = ""
+			}
+			if m.PrivateKey == redactedSecret {
+				m.PrivateKey = ""
+			}
+			if m.Passphrase == redactedSecret {
+				m.Passphrase = ""
 			}
-		case []any:
-			b, _ := json.Marshal(t)
-			_ = json.Unmarshal(b, &machines)
-		default:
-			return fmt.Errorf("YAML must be a list or contain a top-level 'machines' list")
 		}
 
+		var results []result
+		// Read and check keys up front, so bad ones are reported as skipped.
+		baseDir := filepath.Dir(regFile)
+		if regCompose != "" {
+			baseDir = filepath.Dir(regCompose)
+		}
+		usable := machines[:0]
+		for _, m := range machines {
+			if err := loadMachineKey(&m, baseDir); err != nil {
+				skipped = append(skipped, inventory.Skipped{Name: m.Name, Reason: err.Error()})
+				continue
+			}
+			usable = append(usable, m)
+		}
+		machines = usable
+		for _, sk := range skipped {
+			results = append(results, result{
+				Action:   "create",
+				Resource: "machine",
+				Name:     sk.Name,
+				Status:   statusSkipped,
+				Error:    sk.Reason,
+			})
+		}
 		if len(machines) == 0 {
-			fmt.Println("No machines to register.")
+			fmt.Fprintln(os.Stderr, "No machines to register.")
+			if len(results) > 0 {
+				return printResults(results)
+			}
 			return nil
 		}
 
+		// Default SSH password for entries with neither a password nor a key
+		// (prompted only if needed)
+		var defaultPassword string
+		for _, m := range machines {
+			if m.Password == "" && m.PrivateKey == "" {
+				p, err := regPassword.resolve("Default SSH password", false, false)
+				if err != nil {
+					return err
+				}
+				defaultPassword = p
+				break
+			}
+		}
+
+		eng, err := cfg.HostRewriter()
+		if err != nil {
+			return err
+		}
 		cl := client.New(cfg)
 		token, err := cl.GetToken()
 		if err != nil {
@@ -56,25 +134,32 @@ var registerCmd = &cobra.Command{
 
 		var anyFailed bool
 		for _, m := range machines {
-			if m.Name == "" || m.Host == "" || m.Port <= 0 || m.User == "" || m.Password == "" {
-				fmt.Println("Skipping incomplete entry:", m)
-				continue
+			if m.Password == "" && m.PrivateKey == "" {
+				m.Password = defaultPassword
 			}
-			if cl.ShouldRewrite(m.Host) {
-				m.Host = cfg.DockerHostGatewayName
-			}
-			resp, err := cl.PostJSON("/machines", token, m)
-			if err != nil {
-				anyFailed = true
-				fmt.Printf("Failed to add %s: %v\n", m.Name, err)
+			if missing := missingMachineFields(m); len(missing) > 0 {
+				results = append(results, result{
+					Action:   "create",
+					Resource: "machine",
+					Name:     m.Name,
+					Status:   statusSkipped,
+					Error:    "incomplete entry: missing " + strings.Join(missing, ", "),
+				})
 				continue
 			}
-			if resp.StatusCode >= 300 {
+			var applied *rewrite.Applied
+			m.Host, applied = rewriteHost(eng, m.Name, m.Host)
+			resp, err := cl.PostJSON("/machines", token, m)
+			recordAudit(cmd, cl, token, "POST", "/machines", m, resp, err)
+			r := newResult("create", "machine", m.Name, resp, err)
+			r.HostRewrite = applied
+			if r.Status == statusFailed {
 				anyFailed = true
-				fmt.Printf("Failed to add %s: %d %s\n", m.Name, resp.StatusCode, string(resp.Body))
-				continue
 			}
-			fmt.Printf("Added %s (%s:%d)\n", m.Name, m.Host, m.Port)
+			results = append(results, r)
+		}
+		if err := printResults(results); err != nil {
+			return err
 		}
 
 		if anyFailed {
@@ -84,6 +169,104 @@ var registerCmd = &cobra.Command{
 	},
 }
 
+// parseMachinesFile reads machines in the given --format: the native YAML
+// (a list, or a top-level "machines" list) or an inventory format.
+func parseMachinesFile(format string, data []byte) ([]types.MachineCreate, []inventory.Skipped, error) {
+	if format != "" && format != "yaml" {
+		return inventory.Parse(format, data)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("parse YAML: %w", err)
+	}
+	var machines []types.MachineCreate
+	if len(doc.Content) == 0 {
+		return machines, nil, nil
+	}
+	// Decoding the node itself keeps line numbers in the errors.
+	list := doc.Content[0]
+	switch list.Kind {
+	case yaml.MappingNode:
+		var found *yaml.Node
+		for i := 0; i+1 < len(list.Content); i += 2 {
+			if list.Content[i].Value == "machines" {
+				found = list.Content[i+1]
+			}
+		}
+		if found == nil {
+			return machines, nil, nil
+		}
+		list = found
+	case yaml.SequenceNode:
+	default:
+		return nil, nil, fmt.Errorf("YAML must be a list or contain a top-level 'machines' list")
+	}
+	if err := list.Decode(&machines); err != nil {
+		return nil, nil, fmt.Errorf("parse YAML: %w", err)
+	}
+	return machines, nil, nil
+}
+
+// missingMachineFields lists the required fields an entry lacks.
+func missingMachineFields(m types.MachineCreate) []string {
+	var missing []string
+	if m.Name == "" {
+		missing = append(missing, "name")
+	}
+	if m.Host == "" {
+		missing = append(missing, "host")
+	}
+	if m.Port <= 0 {
+		missing = append(missing, "port")
+	}
+	if m.User == "" {
+		missing = append(missing, "user")
+	}
+	if m.Password == "" && m.PrivateKey == "" {
+		missing = append(missing, "password or private_key")
+	}
+	return missing
+}
+
+// loadMachineKey reads m.PrivateKeyFile (relative to baseDir) into
+// m.PrivateKey and checks that the key parses with m.Passphrase, so broken
+// keys are caught before they reach the API. It tells the user on stderr
+// which key will be sent, with its fingerprint.
+func loadMachineKey(m *types.MachineCreate, baseDir string) error {
+	file := m.PrivateKeyFile
+	if file != "" {
+		if m.PrivateKey != "" {
+			return fmt.Errorf("give either private_key or private_key_file, not both")
+		}
+		b, err := sshkey.ReadFile(file, baseDir)
+		if err != nil {
+			return err
+		}
+		m.PrivateKey, m.PrivateKeyFile = string(b), ""
+	}
+	if m.PrivateKey == "" {
+		return nil
+	}
+	signer, err := sshkey.Parse([]byte(m.PrivateKey), m.Passphrase)
+	if err != nil {
+		if file != "" {
+			m.PrivateKey, m.PrivateKeyFile = "", file // retried with a passphrase
+		}
+		return err
+	}
+	if file == "" {
+		file = "private_key"
+	}
+	fmt.Fprintf(os.Stderr, "%s: sending private key %s (%s)\n", m.Name, file, sshkey.Fingerprint(signer))
+	return nil
+}
+
 func init() {
 	registerCmd.Flags().StringVarP(&regFile, "file", "f", "", "Path to machines YAML (e.g., provision/machines.yml)")
+	registerCmd.Flags().StringVar(&regFormat, "format", "yaml", "File format: yaml, "+strings.Join(inventory.Formats, ", "))
+	registerCmd.Flags().StringVar(&regCompose, "from-compose", "", "Discover machines from the published SSH ports of a docker compose file")
+	registerCmd.Flags().StringVar(&regServices, "service-pattern", "", "With --from-compose: only services matching this glob (e.g. 'ssh-*')")
+	registerCmd.Flags().IntVar(&regSSHPort, "ssh-port", 22, "With --from-compose: container port sshd listens on")
+	registerCmd.Flags().StringVar(&regSSHUser, "ssh-user", "root", "With --from-compose: SSH user of the containers")
+	regPassword = addSecretFlags(registerCmd, "password", "", "default SSH password for entries without one", true)
 }
@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/Jeomhps/projet-iac-cli/internal/client"
-	"github.com/Jeomhps/projet-iac-cli/internal/output"
+	"github.com/Jeomhps/projet-iac-cli/internal/fleet"
+	"github.com/Jeomhps/projet-iac-cli/internal/labels"
 	"github.com/spf13/cobra"
 )
 
@@ -12,33 +15,28 @@ var reservationsCmd = &cobra.Command{
 	Use:   "reservations",
 	Short: "List active reservations",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cl := client.New(cfg)
-		token, err := cl.GetToken()
-		if err != nil {
-			return err
-		}
-		resp, err := cl.Get("/reservations", token)
-		if err != nil {
-			return err
-		}
-		fmt.Println(output.FormatJSON(resp.Body, colorMode))
-		return nil
+		return runList(cmd, "/reservations", reservationColumns, "id")
 	},
 }
 
 var (
 	reserveCount    int
 	reserveDuration int
-	reservePassword string
+	reservePassword *secretFlags
 	reserveAsUser   string
+	reserveLabels   string
 )
 
 var reserveCmd = &cobra.Command{
 	Use:   "reserve",
 	Short: "Reserve N machines",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if reserveCount <= 0 || reserveDuration <= 0 || reservePassword == "" {
-			return fmt.Errorf("--count, --duration and --password are required and must be > 0")
+		if reserveCount <= 0 || reserveDuration <= 0 {
+			return fmt.Errorf("--count and --duration are required and must be > 0")
+		}
+		password, err := reservePassword.resolve("Reservation password", true, true)
+		if err != nil {
+			return err
 		}
 		cl := client.New(cfg)
 		token, err := cl.GetToken()
@@ -48,23 +46,84 @@ var reserveCmd = &cobra.Command{
 		payload := map[string]any{
 			"count":                reserveCount,
 			"duration_minutes":     reserveDuration,
-			"reservation_password": reservePassword,
+			"reservation_password": password,
 		}
 		if reserveAsUser != "" {
 			payload["username"] = reserveAsUser
 		}
+		selector, err := reserveSelector(cl, reserveLabels)
+		if err != nil {
+			return err
+		}
+		if selector != "" {
+			payload["label_selector"] = selector
+		}
 		resp, err := cl.PostJSON("/reservations", token, payload)
+		recordAudit(cmd, cl, token, "POST", "/reservations", payload, resp, err)
 		if err != nil {
 			return err
 		}
-		fmt.Println(output.FormatJSON(resp.Body, colorMode))
-		return nil
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		// The reservation itself (machines, hosts, expiry) is what the user
+		// needs next, so it is printed rather than a generic result.
+		return printBody(resp.Body, reservationColumns)
 	},
 }
 
+// reserveSelector returns the label selector to send: the user's, plus
+// !cordoned when machines are cordoned by label (servers with a cordoned
+// field skip those themselves), unless the selector mentions that label.
+// Without -l and label-cordoned machines, no selector is sent at all.
+func reserveSelector(cl *client.Client, s string) (string, error) {
+	sel, err := labels.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range sel {
+		if r.Key == fleet.CordonLabel {
+			return s, nil
+		}
+	}
+	machines, err := getList(cl, "/machines")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: cannot list machines to check for cordoned ones (%v)\n", err)
+		return s, nil
+	}
+	n := labelCordoned(machines)
+	if n == 0 {
+		return s, nil
+	}
+	fmt.Fprintf(os.Stderr, "%d machine(s) cordoned by label: asking the API to skip them with label_selector; servers that ignore it may still hand them out\n", n)
+	if strings.TrimSpace(s) == "" {
+		return "!" + fleet.CordonLabel, nil
+	}
+	return s + ",!" + fleet.CordonLabel, nil
+}
+
+// labelCordoned counts the machines cordoned through the label convention,
+// i.e. labelled cordoned and without a cordoned field of their own.
+func labelCordoned(machines []any) int {
+	n := 0
+	for _, it := range machines {
+		m, ok := it.(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, ok := m["cordoned"].(bool); !ok && fleet.Cordoned(m) {
+			n++
+		}
+	}
+	return n
+}
+
 func init() {
+	addListFlags(reservationsCmd)
+
 	reserveCmd.Flags().IntVar(&reserveCount, "count", 1, "Number of machines")
 	reserveCmd.Flags().IntVar(&reserveDuration, "duration", 60, "Duration in minutes")
-	reserveCmd.Flags().StringVar(&reservePassword, "password", "", "Reservation password to set on machines")
+	reservePassword = addSecretFlags(reserveCmd, "password", "", "reservation password to set on machines", true)
+	reserveCmd.Flags().StringVarP(&reserveLabels, "label-selector", "l", "", "Only reserve machines whose labels match (passed to the API), e.g. os=alpine")
 	reserveCmd.Flags().StringVar(&reserveAsUser, "as-user", "", "Logical username to reserve for (defaults to API user)")
 }
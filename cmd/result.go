@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
+	"github.com/Jeomhps/projet-iac-cli/internal/rewrite"
+)
+
+// result is the outcome of a mutating command, rendered through the selected
+// output format so scripts can consume it.
+type result struct {
+	Action     string          `json:"action"`   // create|delete|reserve|logout|...
+	Resource   string          `json:"resource"` // machine|user|reservation|token
+	Name       string          `json:"name,omitempty"`
+	ID         any             `json:"id,omitempty"`
+	Status     string          `json:"status"` // ok|failed|skipped
+	HTTPStatus int             `json:"http_status,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty"`
+
+	HostRewrite *rewrite.Applied `json:"host_rewrite,omitempty"`
+}
+
+const (
+	statusOK      = "ok"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+)
+
+var resultColumns = []output.Column{
+	output.Col("ACTION", "action"),
+	output.Col("RESOURCE", "resource"),
+	output.Col("NAME", "name", "id"),
+	output.Col("STATUS", "status"),
+	output.Col("ERROR", "error"),
+	output.WideCol("ID", "id"),
+	output.WideCol("HTTP", "http_status"),
+	output.WideCol("REWRITTEN TO", "host_rewrite.to"),
+}
+
+// newResult builds a result from an API call. A transport error or non-2xx
+// response marks it failed.
+func newResult(action, resource, name string, resp *client.HTTPResponse, err error) result {
+	r := result{Action: action, Resource: resource, Name: name, Status: statusOK}
+	if resp != nil {
+		r.HTTPStatus = resp.StatusCode
+		if json.Valid(resp.Body) {
+			r.Response = resp.Body
+			var m map[string]any
+			if json.Unmarshal(resp.Body, &m) == nil {
+				r.ID = m["id"]
+			}
+		}
+		if err == nil {
+			err = resp.Err()
+		}
+	}
+	if err != nil {
+		r.Status = statusFailed
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// printResult renders one result.
+func printResult(r result) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return printBody(b, resultColumns)
+}
+
+// printResults renders a list of results.
+func printResults(rs []result) error {
+	if rs == nil {
+		rs = []result{}
+	}
+	b, err := json.Marshal(rs)
+	if err != nil {
+		return err
+	}
+	return printBody(b, resultColumns)
+}
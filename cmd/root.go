@@ -5,9 +5,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+	_ "time/tzdata" // --tz must work on Windows lab PCs without zoneinfo
 
 	"github.com/Jeomhps/projet-iac-cli/internal/client"
 	"github.com/Jeomhps/projet-iac-cli/internal/configloader"
+	"github.com/Jeomhps/projet-iac-cli/internal/hostgw"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -26,16 +30,38 @@ var (
 	flagDockerHostGateway string
 	flagKeychainMode      string
 	flagColorMode         string
+	flagProfile           string
+	flagOutput            string
+	flagNoHeaders         bool
+	flagColumns           []string
+	flagQuery             string
+	flagQueryFirst        bool
+	flagUTC               bool
+	flagTZ                string
+	flagAbsolute          bool
 
 	// final output color mode resolved from config/env/flags
 	colorMode string
+	// final output format ("" = table on a TTY, json otherwise)
+	outputFormat string
+	// time zone name for displayed timestamps ("" = local)
+	timeZone string
+	// selected config profile ("" when none)
+	profileName string
 )
 
+// dockerHostSetting is the configured --docker-host value before "auto" is
+// resolved, for doctor.
+var dockerHostSetting string
+
 var rootCmd = &cobra.Command{
 	Use:   "projet-iac-cli",
 	Short: "Projet IAC CLI",
 	Long:  "CLI for the Projet IAC API (manage users, machines, and reservations).",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Flags parsed fine: from here on, errors are not usage errors
+		cmd.SilenceUsage = true
+
 		// Build final cfg from: defaults <- config file <- env <- explicit flags
 		if err := buildConfig(cmd); err != nil {
 			return err
@@ -43,6 +69,18 @@ var rootCmd = &cobra.Command{
 
 		// Normalize a couple of fields
 		cfg.APIBase = strings.TrimRight(cfg.APIBase, "/")
+		if !output.ValidFormat(outputFormat) {
+			return fmt.Errorf("unknown output format %q", outputFormat)
+		}
+		td := output.TimeDisplay{Absolute: flagAbsolute}
+		if timeZone != "" {
+			loc, err := time.LoadLocation(timeZone)
+			if err != nil {
+				return fmt.Errorf("time zone %q: %w", timeZone, err)
+			}
+			td.Location = loc
+		}
+		output.SetTimeDisplay(td)
 
 		return nil
 	},
@@ -61,20 +99,31 @@ func init() {
 		RewriteLocalhost:      true,
 		DockerHostGatewayName: "host.docker.internal",
 		KeychainMode:          "auto", // auto|on|off
+		TokenRefreshWindow:    5 * time.Minute,
 	}
 	colorMode = "auto" // auto|always|never
 
 	// Flags (bind to separate vars so we can decide precedence)
 	rootCmd.PersistentFlags().StringVar(&flagConfigPath, "config", getenv("CONFIG_FILE", configloader.DefaultPath()), "Path to config file (YAML)")
+	rootCmd.PersistentFlags().StringVar(&flagProfile, "profile", "", "Config profile to use (from 'profiles:' in the config file)")
 
 	rootCmd.PersistentFlags().StringVar(&flagAPIBase, "api-base", cfg.APIBase, "Base URL (e.g., https://localhost)")
 
 	rootCmd.PersistentFlags().BoolVar(&flagVerifyTLS, "verify-tls", cfg.VerifyTLS, "Verify TLS certificates")
 	rootCmd.PersistentFlags().StringVar(&flagTokenFile, "token-file", cfg.TokenFile, "Token cache file (~/.projet-iac/token.json) (used if keychain unavailable/disabled)")
-	rootCmd.PersistentFlags().BoolVar(&flagRewriteLocalhost, "rewrite-localhost", cfg.RewriteLocalhost, "Rewrite localhost/127.0.0.1 to host.docker.internal")
-	rootCmd.PersistentFlags().StringVar(&flagDockerHostGateway, "docker-host", cfg.DockerHostGatewayName, "Name used when rewriting localhost")
+	rootCmd.PersistentFlags().BoolVar(&flagRewriteLocalhost, "rewrite-localhost", cfg.RewriteLocalhost, "Rewrite loopback hosts (localhost, 127.0.0.0/8, ::1, 0.0.0.0, ::) to the Docker host name")
+	rootCmd.PersistentFlags().StringVar(&flagDockerHostGateway, "docker-host", cfg.DockerHostGatewayName, "Name used when rewriting localhost (and by host_rewrites without a target); 'auto' detects Docker/Podman")
 	rootCmd.PersistentFlags().StringVar(&flagKeychainMode, "keychain", cfg.KeychainMode, "Keychain usage: auto|on|off")
 	rootCmd.PersistentFlags().StringVar(&flagColorMode, "color", colorMode, "Colorize JSON output: auto|always|never")
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", "", "Output format: table|wide|pretty|json|ndjson|yaml|csv|tsv|go-template=...|go-template-file=...|jsonpath=...|jsonpath-file=... (default table on a TTY, pretty otherwise)")
+	rootCmd.PersistentFlags().BoolVar(&flagNoHeaders, "no-headers", false, "Omit table/CSV/TSV headers")
+	rootCmd.PersistentFlags().StringVarP(&flagQuery, "query", "q", "", "jq expression applied to the response before formatting (built in, no jq needed); results are always a list")
+	rootCmd.PersistentFlags().BoolVar(&flagQueryFirst, "query-first", false, "With --query, output only the first result, as-is (null if none)")
+	rootCmd.PersistentFlags().StringVar(&flagOutputFile, "output-file", "", "Write results atomically to this file instead of stdout")
+	rootCmd.PersistentFlags().BoolVar(&flagUTC, "utc", false, "Show timestamps in UTC")
+	rootCmd.PersistentFlags().StringVar(&flagTZ, "tz", "", "Show timestamps in this IANA time zone (e.g. Europe/Paris)")
+	rootCmd.PersistentFlags().BoolVar(&flagAbsolute, "absolute", false, "Show absolute timestamps only (no 'in 23m' / '4m ago')")
+	rootCmd.PersistentFlags().StringSliceVar(&flagColumns, "columns", nil, "Columns for table/csv/tsv output, as JSON keys (e.g. name,host,port)")
 
 	rootCmd.Version = fmt.Sprintf("%s (%s)", version, commit)
 
@@ -96,30 +145,36 @@ func buildConfig(cmd *cobra.Command) error {
 	if confPath == "" {
 		confPath = configloader.DefaultPath()
 	}
-	if fc, exists, err := configloader.LoadFile(confPath); err != nil {
+	fc, exists, err := configloader.LoadFile(confPath)
+	if err != nil {
 		return fmt.Errorf("load config file %s: %w", confPath, err)
-	} else if exists {
-		if fc.APIBase != nil {
-			cfg.APIBase = *fc.APIBase
-		}
+	}
 
-		if fc.VerifyTLS != nil {
-			cfg.VerifyTLS = *fc.VerifyTLS
-		}
-		if fc.TokenFile != nil {
-			cfg.TokenFile = *fc.TokenFile
-		}
-		if fc.RewriteLocalhost != nil {
-			cfg.RewriteLocalhost = *fc.RewriteLocalhost
-		}
-		if fc.DockerHostGatewayName != nil {
-			cfg.DockerHostGatewayName = *fc.DockerHostGatewayName
+	// Profile: flag <- env <- config file default
+	profileName = ""
+	if exists && fc.Profile != nil {
+		profileName = *fc.Profile
+	}
+	if v, ok := getenvOpt("IAC_PROFILE"); ok {
+		profileName = v
+	}
+	if cmd.Flags().Changed("profile") {
+		profileName = flagProfile
+	}
+	profileName = strings.TrimSpace(profileName)
+
+	if exists {
+		if err := applyFileConfig(fc); err != nil {
+			return err
 		}
-		if fc.KeychainMode != nil && *fc.KeychainMode != "" {
-			cfg.KeychainMode = *fc.KeychainMode
+	}
+	if profileName != "" {
+		pc, ok := fc.Profiles[profileName]
+		if !ok {
+			return fmt.Errorf("profile %q not found in %s", profileName, confPath)
 		}
-		if fc.ColorMode != nil && *fc.ColorMode != "" {
-			colorMode = *fc.ColorMode
+		if err := applyFileConfig(pc); err != nil {
+			return fmt.Errorf("profile %s: %w", profileName, err)
 		}
 	}
 
@@ -163,6 +218,24 @@ func buildConfig(cmd *cobra.Command) error {
 			colorMode = strings.ToLower(strings.TrimSpace(v))
 		}
 	}
+	if !flagChanged("output") {
+		if v, ok := getenvOpt("OUTPUT"); ok {
+			outputFormat = strings.TrimSpace(v)
+		}
+	}
+	if v, ok := os.LookupEnv("AUDIT_LOG"); ok {
+		auditLogPath = strings.TrimSpace(v)
+	}
+	if v, ok := envBoolOpt("REMEMBER_CREDENTIALS"); ok {
+		cfg.RememberCredentials = v
+	}
+	if v, ok := getenvOpt("TOKEN_REFRESH_WINDOW"); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return fmt.Errorf("TOKEN_REFRESH_WINDOW: %w", err)
+		}
+		cfg.TokenRefreshWindow = d
+	}
 
 	// 3) Explicit flags override everything
 	if flagChanged("api-base") {
@@ -187,6 +260,94 @@ func buildConfig(cmd *cobra.Command) error {
 	if flagChanged("color") {
 		colorMode = strings.ToLower(strings.TrimSpace(flagColorMode))
 	}
+	if flagChanged("output") {
+		outputFormat = strings.TrimSpace(flagOutput)
+	}
+	if flagUTC && flagChanged("tz") {
+		return fmt.Errorf("use either --utc or --tz, not both")
+	}
+	if flagChanged("tz") {
+		timeZone = strings.TrimSpace(flagTZ)
+	}
+	if flagUTC {
+		timeZone = "UTC"
+	}
+
+	// Results written to --output-file are formatted for a file, whatever
+	// stdout is: the non-terminal default format and no colour.
+	if flagOutputFile != "" {
+		if strings.TrimSpace(outputFormat) == "" {
+			outputFormat = output.Pretty
+		}
+		if colorMode == "" || colorMode == "auto" {
+			colorMode = "never"
+		}
+	}
 
+	// "auto" picks the gateway for the local container runtime.
+	dockerHostSetting = cfg.DockerHostGatewayName
+	if strings.EqualFold(strings.TrimSpace(cfg.DockerHostGatewayName), "auto") {
+		cfg.DockerHostGatewayName = hostgw.Detector{}.Detect().Target
+	}
+
+	// Catch bad host_rewrites before any command runs.
+	if _, err := cfg.HostRewriter(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	return nil
+}
+
+// applyFileConfig overlays the fields present in a config file section.
+func applyFileConfig(fc configloader.FileConfig) error {
+	if fc.APIBase != nil {
+		cfg.APIBase = *fc.APIBase
+	}
+
+	if fc.VerifyTLS != nil {
+		cfg.VerifyTLS = *fc.VerifyTLS
+	}
+	if fc.TokenFile != nil {
+		cfg.TokenFile = *fc.TokenFile
+	}
+	if fc.RewriteLocalhost != nil {
+		cfg.RewriteLocalhost = *fc.RewriteLocalhost
+	}
+	if fc.DockerHostGatewayName != nil && fc.DockerHost != nil {
+		return fmt.Errorf("set either docker_host or docker_host_gateway_name, not both")
+	}
+	if fc.DockerHostGatewayName != nil {
+		cfg.DockerHostGatewayName = *fc.DockerHostGatewayName
+	}
+	if fc.DockerHost != nil {
+		cfg.DockerHostGatewayName = *fc.DockerHost
+	}
+	if fc.HostRewrites != nil {
+		cfg.HostRewrites = fc.HostRewrites
+	}
+	if fc.KeychainMode != nil && *fc.KeychainMode != "" {
+		cfg.KeychainMode = *fc.KeychainMode
+	}
+	if fc.ColorMode != nil && *fc.ColorMode != "" {
+		colorMode = *fc.ColorMode
+	}
+	if fc.Output != nil && *fc.Output != "" {
+		outputFormat = strings.TrimSpace(*fc.Output)
+	}
+	if fc.TimeZone != nil {
+		timeZone = strings.TrimSpace(*fc.TimeZone)
+	}
+	if fc.AuditLog != nil {
+		auditLogPath = *fc.AuditLog
+	}
+	if fc.RememberCredentials != nil {
+		cfg.RememberCredentials = *fc.RememberCredentials
+	}
+	if fc.TokenRefreshWindow != nil && *fc.TokenRefreshWindow != "" {
+		d, err := time.ParseDuration(*fc.TokenRefreshWindow)
+		if err != nil {
+			return fmt.Errorf("token_refresh_window: %w", err)
+		}
+		cfg.TokenRefreshWindow = d
+	}
 	return nil
 }
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/secretinput"
+	"github.com/spf13/cobra"
+)
+
+// secretFlags binds the standard set of secret-input flags to a command:
+// --<name>, --<name>-stdin, --<name>-file, --<name>-env and --<name>-cmd.
+type secretFlags struct {
+	name string
+	src  secretinput.Source
+}
+
+// addSecretFlags registers the secret flags on cmd. shorthand applies to the
+// plain --<name> flag; plain=false omits it entirely.
+func addSecretFlags(cmd *cobra.Command, name, shorthand, what string, plain bool) *secretFlags {
+	f := &secretFlags{name: name}
+	fl := cmd.Flags()
+	if plain {
+		fl.StringVarP(&f.src.Value, name, shorthand, "", what+" (insecure: visible in process list; omit to prompt)")
+	}
+	fl.BoolVar(&f.src.Stdin, name+"-stdin", false, "Read "+what+" from STDIN")
+	fl.StringVar(&f.src.File, name+"-file", "", "Read "+what+" from a file")
+	fl.StringVar(&f.src.Env, name+"-env", "", "Read "+what+" from the named environment variable")
+	fl.StringVar(&f.src.Cmd, name+"-cmd", "", "Read "+what+" from the output of a shell command")
+	return f
+}
+
+// provided reports whether any non-interactive source was given.
+func (f *secretFlags) provided() bool {
+	return f.src.Provided()
+}
+
+// stdin reports whether the secret is read from STDIN (so STDIN is not free for prompts).
+func (f *secretFlags) stdin() bool {
+	return f.src.Stdin
+}
+
+// resolve returns the secret from the given flags, falling back to a terminal
+// prompt. With required=false and no source/TTY it returns "".
+func (f *secretFlags) resolve(label string, confirm, required bool) (string, error) {
+	if f.provided() {
+		if f.src.Plain() {
+			fmt.Fprintf(os.Stderr, "Warning: --%s exposes the secret in the process list; prefer --%s-stdin, --%s-file, --%s-env or --%s-cmd.\n",
+				f.name, f.name, f.name, f.name, f.name)
+		}
+		v, err := f.src.Read()
+		if err != nil {
+			return "", fmt.Errorf("--%s: %w", f.name, err)
+		}
+		return v, nil
+	}
+	if !secretinput.IsTerminal() {
+		if !required {
+			return "", nil
+		}
+		return "", fmt.Errorf("stdin is not a TTY. Use --%s-stdin, --%s-file, --%s-env or --%s-cmd to provide the %s",
+			f.name, f.name, f.name, f.name, label)
+	}
+	return secretinput.Prompt(label, confirm)
+}
@@ -2,15 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"io"
-	"os"
 	"strings"
 
 	"github.com/Jeomhps/projet-iac-cli/internal/client"
-	"github.com/Jeomhps/projet-iac-cli/internal/output"
 	"github.com/Jeomhps/projet-iac-cli/internal/types"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 )
 
 var usersCmd = &cobra.Command{
@@ -22,24 +18,14 @@ var usersListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List users (admin)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cl := client.New(cfg)
-		token, err := cl.GetToken()
-		if err != nil {
-			return err
-		}
-		resp, err := cl.Get("/users", token)
-		if err != nil {
-			return err
-		}
-		fmt.Println(output.FormatJSON(resp.Body, colorMode))
-		return nil
+		return runList(cmd, "/users", userColumns, "username")
 	},
 }
 
 var (
 	uCreateUsername string
 	uCreateIsAdmin  bool
-	uPasswordStdin  bool
+	uPassword       *secretFlags
 	uNoConfirm      bool
 )
 
@@ -51,42 +37,9 @@ var usersCreateCmd = &cobra.Command{
 			return fmt.Errorf("--username is required")
 		}
 
-		// Get password securely
-		var password string
-		if uPasswordStdin {
-			b, err := io.ReadAll(os.Stdin)
-			if err != nil {
-				return fmt.Errorf("reading password from stdin: %w", err)
-			}
-			password = strings.TrimRight(string(b), "\r\n")
-			if password == "" {
-				return fmt.Errorf("password from stdin is empty")
-			}
-		} else {
-			if !term.IsTerminal(int(os.Stdin.Fd())) {
-				return fmt.Errorf("stdin is not a TTY. Use --password-stdin to provide the password via stdin")
-			}
-			fmt.Print("Password: ")
-			p1, err := term.ReadPassword(int(os.Stdin.Fd()))
-			fmt.Println()
-			if err != nil {
-				return fmt.Errorf("reading password: %w", err)
-			}
-			if len(p1) == 0 {
-				return fmt.Errorf("password cannot be empty")
-			}
-			if !uNoConfirm {
-				fmt.Print("Confirm password: ")
-				p2, err := term.ReadPassword(int(os.Stdin.Fd()))
-				fmt.Println()
-				if err != nil {
-					return fmt.Errorf("reading confirmation: %w", err)
-				}
-				if string(p1) != string(p2) {
-					return fmt.Errorf("passwords do not match")
-				}
-			}
-			password = string(p1)
+		password, err := uPassword.resolve("Password", !uNoConfirm, true)
+		if err != nil {
+			return err
 		}
 
 		cl := client.New(cfg)
@@ -100,14 +53,14 @@ var usersCreateCmd = &cobra.Command{
 			IsAdmin:  uCreateIsAdmin,
 		}
 		resp, err := cl.PostJSON("/users", token, payload)
+		recordAudit(cmd, cl, token, "POST", "/users", payload, resp, err)
 		if err != nil {
 			return err
 		}
-		if resp.StatusCode >= 300 {
-			return fmt.Errorf("create failed: %d %s", resp.StatusCode, string(resp.Body))
+		if err := resp.Err(); err != nil {
+			return fmt.Errorf("create failed: %w", err)
 		}
-		fmt.Println(output.FormatJSON(resp.Body, colorMode))
-		return nil
+		return printResult(newResult("create", "user", uCreateUsername, resp, nil))
 	},
 }
 
@@ -128,14 +81,14 @@ var usersDeleteCmd = &cobra.Command{
 			return err
 		}
 		resp, err := cl.Delete("/users/"+uDeleteUsername, token)
+		recordAudit(cmd, cl, token, "DELETE", "/users/"+uDeleteUsername, nil, resp, err)
 		if err != nil {
 			return err
 		}
-		if resp.StatusCode >= 300 {
-			return fmt.Errorf("delete failed: %d %s", resp.StatusCode, string(resp.Body))
+		if err := resp.Err(); err != nil {
+			return fmt.Errorf("delete failed: %w", err)
 		}
-		fmt.Println("Deleted user", uDeleteUsername)
-		return nil
+		return printResult(newResult("delete", "user", uDeleteUsername, resp, nil))
 	},
 }
 
@@ -145,14 +98,15 @@ func init() {
 
 	// users subcommands
 	usersCmd.AddCommand(usersListCmd)
-usersCmd.AddCommand(usersCreateCmd)
+	usersCmd.AddCommand(usersCreateCmd)
 	usersCmd.AddCommand(usersDeleteCmd)
 
 	// Flags
+	addListFlags(usersListCmd)
 	usersCreateCmd.Flags().StringVar(&uCreateUsername, "username", "", "Username")
 	usersCreateCmd.Flags().BoolVar(&uCreateIsAdmin, "admin", false, "Set user as admin")
-	usersCreateCmd.Flags().BoolVar(&uPasswordStdin, "password-stdin", false, "Read password from STDIN (for automation)")
-	usersCreateCmd.Flags().BoolVar(&uNoConfirm, "no-confirm", false, "Skip password confirmation (useful with --password-stdin)")
+	uPassword = addSecretFlags(usersCreateCmd, "password", "", "password", false)
+	usersCreateCmd.Flags().BoolVar(&uNoConfirm, "no-confirm", false, "Skip password confirmation when prompting")
 
 	usersDeleteCmd.Flags().StringVar(&uDeleteUsername, "username", "", "Username to delete")
 }
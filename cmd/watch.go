@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/client"
+	"github.com/Jeomhps/projet-iac-cli/internal/listing"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
+	"github.com/Jeomhps/projet-iac-cli/internal/watch"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	listWatch    bool
+	listInterval time.Duration
+)
+
+// ANSI sequences used when redrawing on a terminal
+const (
+	ansiClear  = "\033[H\033[2J"
+	ansiReset  = "\033[0m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiRed    = "\033[31m"
+)
+
+// runList fetches a list endpoint and prints it, or keeps polling it with
+// --watch. key identifies items between polls (e.g. "name").
+func runList(cmd *cobra.Command, path string, cols []output.Column, key string) error {
+	cl := client.New(cfg)
+	if listWatch {
+		return watchList(cmd, cl, path, cols, key)
+	}
+	token, err := cl.GetToken()
+	if err != nil {
+		return err
+	}
+	if canStream() {
+		resp, err := cl.GetStream(path, token)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		return output.WriteJSON(resultWriter(), resp.Body, output.UseColor(colorMode))
+	}
+	resp, err := cl.Get(path, token)
+	if err != nil {
+		return err
+	}
+	if err := resp.Err(); err != nil {
+		return err
+	}
+	return printList(resp.Body, cols)
+}
+
+// getList GETs a list endpoint without applying the list flags.
+func getList(cl *client.Client, path string) ([]any, error) {
+	token, err := cl.GetToken()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := cl.Get(path, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	var items []any
+	if err := json.Unmarshal(resp.Body, &items); err != nil {
+		return nil, fmt.Errorf("GET %s: expected a JSON list: %w", path, err)
+	}
+	return items, nil
+}
+
+// fetchItems GETs a list endpoint and applies the list flags.
+func fetchItems(cl *client.Client, path string) ([]any, error) {
+	items, err := getList(cl, path)
+	if err != nil {
+		return nil, err
+	}
+	return listing.Process(items, listOpts)
+}
+
+// watchList polls path every --interval until interrupted. On a terminal with
+// table output it redraws in place, marking added (+), changed (~) and removed
+// (-) rows; otherwise it writes one NDJSON event per changed item.
+func watchList(cmd *cobra.Command, cl *client.Client, path string, cols []output.Column, key string) error {
+	if flagQuery != "" {
+		return fmt.Errorf("--query cannot be combined with --watch")
+	}
+	if flagOutputFile != "" {
+		return fmt.Errorf("--output-file cannot be combined with --watch")
+	}
+	if listInterval <= 0 {
+		return fmt.Errorf("--interval must be > 0")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	format := output.ResolveFormat(outputFormat)
+	redraw := (format == output.Table || format == output.Wide) && term.IsTerminal(int(os.Stdout.Fd()))
+	sel := flagColumns
+	if len(sel) == 0 && len(listOpts.Fields) > 0 {
+		sel = listOpts.Fields
+	}
+	if len(sel) > 0 {
+		cols = output.SelectColumns(sel)
+	}
+
+	ticker := time.NewTicker(listInterval)
+	defer ticker.Stop()
+	var prev []any
+	first := true
+	enc := json.NewEncoder(os.Stdout)
+	for {
+		items, err := fetchItems(cl, path)
+		switch {
+		case redraw:
+			var events []watch.Event
+			if err == nil && !first {
+				events = watch.Diff(prev, items, key)
+			}
+			shown := items
+			if err != nil {
+				shown = prev
+			}
+			drawWatch(cmd, shown, events, key, cols, format == output.Wide, err)
+		case err != nil:
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		default:
+			for _, ev := range watch.Diff(prev, items, key) {
+				if err := enc.Encode(ev); err != nil {
+					return err
+				}
+			}
+		}
+		if err == nil {
+			prev, first = items, false
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// drawWatch clears the terminal and draws the current table.
+func drawWatch(cmd *cobra.Command, items []any, events []watch.Event, key string, cols []output.Column, wide bool, pollErr error) {
+	kind := map[string]string{}
+	var removed []any
+	for _, ev := range events {
+		if ev.Type == watch.Removed {
+			removed = append(removed, ev.Item)
+			continue
+		}
+		kind[ev.Key] = ev.Type
+	}
+
+	all := make([]any, 0, len(items)+len(removed))
+	marks := make([]string, 0, cap(all))
+	for _, it := range items {
+		all = append(all, it)
+		switch kind[watch.KeyOf(it, key)] {
+		case watch.Added:
+			marks = append(marks, "+")
+		case watch.Modified:
+			marks = append(marks, "~")
+		default:
+			marks = append(marks, " ")
+		}
+	}
+	for _, it := range removed {
+		all = append(all, it)
+		marks = append(marks, "-")
+	}
+
+	color := output.UseColor(colorMode)
+	var b strings.Builder
+	b.WriteString(ansiClear)
+	fmt.Fprintf(&b, "Every %s: %s    %s\n", listInterval, cmd.CommandPath(), time.Now().Format("15:04:05"))
+	if pollErr != nil {
+		line := "Error: " + pollErr.Error()
+		if color {
+			line = ansiRed + line + ansiReset
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+	if len(all) == 0 {
+		b.WriteString("No items.\n")
+	} else {
+		lines := output.TableLines(all, cols, wide, flagNoHeaders, marks)
+		offset := len(lines) - len(all) // header line, if any
+		for i, line := range lines {
+			if color && i >= offset {
+				switch marks[i-offset] {
+				case "+":
+					line = ansiGreen + line + ansiReset
+				case "~":
+					line = ansiYellow + line + ansiReset
+				case "-":
+					line = ansiRed + line + ansiReset
+				}
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	fmt.Fprint(os.Stdout, b.String())
+}
@@ -1,10 +1,7 @@
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/Jeomhps/projet-iac-cli/internal/client"
-	"github.com/Jeomhps/projet-iac-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -21,7 +18,9 @@ var whoamiCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		fmt.Println(output.FormatJSON(resp.Body, colorMode))
-		return nil
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		return printBody(resp.Body, userColumns)
 	},
 }
@@ -0,0 +1,378 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Entry is one line of the audit log. Entries are hash-chained: Hash covers the
+// entry (with Hash empty) and PrevHash, so editing or removing a line breaks
+// every later hash; the Head covers the end of the log.
+type Entry struct {
+	Time     time.Time       `json:"time"`
+	OSUser   string          `json:"os_user"`
+	APIUser  string          `json:"api_user,omitempty"`
+	Profile  string          `json:"profile,omitempty"`
+	Command  string          `json:"command"`
+	Method   string          `json:"method"`
+	Endpoint string          `json:"endpoint"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
+	Status   int             `json:"status"`
+	Error    string          `json:"error,omitempty"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash,omitempty"`
+}
+
+// DefaultPath returns ~/.projet-iac/audit.log
+func DefaultPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".projet-iac", "audit.log")
+}
+
+// computeHash returns the hex SHA-256 of the entry with its Hash cleared.
+func computeHash(e Entry) string {
+	e.Hash = ""
+	b, _ := json.Marshal(e)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Head is the anchor of the chain, kept next to the log in HeadPath: the
+// number of entries and the hash of the last one. It lets Verify notice
+// entries removed from the end, which the chain alone cannot show.
+type Head struct {
+	Count int    `json:"count"`
+	Hash  string `json:"hash"`
+}
+
+// HeadPath returns the path of the head file of the log at path.
+func HeadPath(path string) string {
+	return path + ".head"
+}
+
+// lock takes an exclusive lock on path+".lock", shared by every process
+// writing the log at path, and returns the function releasing it.
+func lock(path string) (func(), error) {
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("lock %s: %w", f.Name(), err)
+	}
+	return func() {
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// Append chains e to the last entry in the log at path, writes it and moves
+// the head forward. Only the end of the log is read. The whole sequence runs
+// under a file lock, so concurrent CLI processes do not fork the chain.
+// The directory is created with 0700 and the files with 0600 permissions.
+func Append(path string, e Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	unlock, err := lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	last, err := lastEntry(path)
+	if err != nil {
+		return err
+	}
+	prev := ""
+	if last != nil {
+		prev = last.Hash
+	}
+	head, err := readHead(path)
+	if err != nil {
+		return err
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if len(e.Payload) > 0 {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, e.Payload); err != nil {
+			return fmt.Errorf("audit payload: %w", err)
+		}
+		e.Payload = buf.Bytes()
+	}
+	e.PrevHash = prev
+	e.Hash = computeHash(e)
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	count, ok, err := nextCount(path, head, prev)
+	if err != nil || !ok {
+		return err
+	}
+	return writeHead(path, Head{Count: count, Hash: e.Hash})
+}
+
+// nextCount works out the entry count after an append whose previous hash
+// was prev. ok is false when the head does not match the log, e.g. after
+// entries were removed: it is then left alone so Verify reports it.
+func nextCount(path string, head *Head, prev string) (int, bool, error) {
+	switch {
+	case head != nil && head.Hash == prev:
+		return head.Count + 1, true, nil
+	case head == nil && prev == "":
+		return 1, true, nil
+	}
+	// No head for an existing log (written before heads existed), or the
+	// previous append stopped before moving the head: count once.
+	entries, err := Read(path)
+	if err != nil {
+		return 0, false, err
+	}
+	if head != nil && (head.Count > len(entries) || head.Count > 0 && entries[head.Count-1].Hash != head.Hash) {
+		return 0, false, nil
+	}
+	return len(entries), true, nil
+}
+
+// lastEntry returns the final entry of the log, reading the file backwards
+// from its end, or nil for a missing or empty log.
+func lastEntry(path string) (*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	const chunk = 4096
+	var tail []byte
+	for pos := st.Size(); pos > 0; {
+		n := int64(chunk)
+		if pos < n {
+			n = pos
+		}
+		pos -= n
+		buf := make([]byte, n)
+		if _, err := f.ReadAt(buf, pos); err != nil {
+			return nil, err
+		}
+		tail = append(buf, tail...)
+		trimmed := bytes.TrimRight(tail, "\r\n\t ")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			tail = trimmed[i+1:]
+			break
+		}
+	}
+	line := bytes.TrimSpace(tail)
+	if len(line) == 0 {
+		return nil, nil
+	}
+	var e Entry
+	if err := json.Unmarshal(line, &e); err != nil {
+		return nil, fmt.Errorf("last entry: %w", err)
+	}
+	return &e, nil
+}
+
+// readHead reads the head of the log at path; a missing head yields nil.
+func readHead(path string) (*Head, error) {
+	b, err := os.ReadFile(HeadPath(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var h Head
+	if err := json.Unmarshal(b, &h); err != nil {
+		return nil, fmt.Errorf("%s: %w", HeadPath(path), err)
+	}
+	return &h, nil
+}
+
+// writeHead replaces the head file atomically.
+func writeHead(path string, h Head) error {
+	b, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(HeadPath(path))+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), HeadPath(path))
+}
+
+// Read returns all entries of the log; a missing file yields no entries.
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return decode(f)
+}
+
+func decode(r io.Reader) ([]Entry, error) {
+	var out []Entry
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return out, fmt.Errorf("line %d: %w", line, err)
+		}
+		out = append(out, e)
+	}
+	return out, sc.Err()
+}
+
+// Verify checks the hash chain, then the head, and returns the number of
+// valid entries. The error names the first entry (1-based) that fails, or
+// reports entries missing from the end.
+func Verify(path string) (int, error) {
+	if _, err := os.Stat(path); err == nil {
+		// Wait for an append in progress, which may not have moved the head
+		// yet. Readers without write access verify without the lock.
+		if unlock, err := lock(path); err == nil {
+			defer unlock()
+		}
+	}
+	entries, err := Read(path)
+	if err != nil {
+		return 0, err
+	}
+	prev := ""
+	for i, e := range entries {
+		if e.PrevHash != prev {
+			return i, fmt.Errorf("entry %d: chain broken (previous hash mismatch)", i+1)
+		}
+		if computeHash(e) != e.Hash {
+			return i, fmt.Errorf("entry %d: content does not match its hash", i+1)
+		}
+		prev = e.Hash
+	}
+
+	head, err := readHead(path)
+	if err != nil {
+		return len(entries), err
+	}
+	switch {
+	case head == nil && len(entries) > 0:
+		return len(entries), fmt.Errorf("%s is missing: cannot tell whether entries were removed from the end", HeadPath(path))
+	case head == nil:
+	case head.Count > len(entries):
+		return len(entries), fmt.Errorf("head records %d entries but the log has %d: entries removed from the end", head.Count, len(entries))
+	case head.Count > 0 && entries[head.Count-1].Hash != head.Hash:
+		return len(entries), fmt.Errorf("entry %d does not match the head hash: the end of the log was replaced", head.Count)
+	}
+	// Entries past the head can only come from an append interrupted
+	// before it moved the head; the chain still covers them.
+	return len(entries), nil
+}
+
+// secretKeys are payload keys whose values never reach the log.
+var secretKeys = []string{"password", "passphrase", "secret", "token", "private_key"}
+
+// Redact returns a JSON copy of payload with secret-looking fields replaced.
+func Redact(payload any) json.RawMessage {
+	if payload == nil {
+		return nil
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil
+	}
+	out, _ := json.Marshal(redactValue(v))
+	return out
+}
+
+func redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if isSecretKey(k) {
+				if s, ok := val.(string); ok && s == "" {
+					continue
+				}
+				t[k] = "[REDACTED]"
+				continue
+			}
+			t[k] = redactValue(val)
+		}
+		return t
+	case []any:
+		for i := range t {
+			t[i] = redactValue(t[i])
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+func isSecretKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, s := range secretKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -14,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Jeomhps/projet-iac-cli/internal/rewrite"
 	"github.com/Jeomhps/projet-iac-cli/internal/securestore"
 )
 
@@ -24,12 +25,23 @@ type Config struct {
 	RewriteLocalhost      bool
 	DockerHostGatewayName string
 	KeychainMode          string // "auto" (default), "on", "off"
+
+	// HostRewrites are tried before the built-in loopback rules.
+	HostRewrites []rewrite.Rule
+
+	// RememberCredentials enables silent re-login from credentials kept in the
+	// OS keychain when the cached token is expired or within TokenRefreshWindow
+	// of expiry.
+	RememberCredentials bool
+	TokenRefreshWindow  time.Duration
 }
 
 type HTTPResponse struct {
 	StatusCode int
 	Body       []byte
 	Header     http.Header
+	Method     string
+	Path       string // request path relative to the API base
 }
 
 type Client struct {
@@ -44,6 +56,7 @@ func New(cfg Config) *Client {
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: !cfg.VerifyTLS, // dev: allow self-signed
 		},
+		ResponseHeaderTimeout: 60 * time.Second,
 	}
 	// Determine store
 	mode := securestore.Mode(strings.ToLower(strings.TrimSpace(cfg.KeychainMode)))
@@ -73,22 +86,31 @@ func (c *Client) url(path string) string {
 	return c.cfg.APIBase + path
 }
 
-func (c *Client) ShouldRewrite(host string) bool {
-	if !c.cfg.RewriteLocalhost {
-		return false
+// HostRewriter compiles the configured host rewrite rules, followed by the
+// built-in loopback rules unless RewriteLocalhost is off. Rules without a
+// target rewrite to DockerHostGatewayName.
+func (c Config) HostRewriter() (*rewrite.Engine, error) {
+	rules := append([]rewrite.Rule{}, c.HostRewrites...)
+	if c.RewriteLocalhost {
+		rules = append(rules, rewrite.DefaultRules()...)
 	}
-	h := strings.TrimSpace(strings.ToLower(host))
-	return h == "localhost" || h == "127.0.0.1"
+	return rewrite.New(rules, c.DockerHostGatewayName)
 }
 
-func (c *Client) do(req *http.Request) (*HTTPResponse, error) {
+func (c *Client) do(req *http.Request, path string) (*HTTPResponse, error) {
 	res, err := c.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 	b, _ := io.ReadAll(res.Body)
-	return &HTTPResponse{StatusCode: res.StatusCode, Body: b, Header: res.Header.Clone()}, nil
+	return &HTTPResponse{
+		StatusCode: res.StatusCode,
+		Body:       b,
+		Header:     res.Header.Clone(),
+		Method:     req.Method,
+		Path:       path,
+	}, nil
 }
 
 func (c *Client) Get(path string, token string) (*HTTPResponse, error) {
@@ -96,17 +118,70 @@ func (c *Client) Get(path string, token string) (*HTTPResponse, error) {
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
-	return c.do(req)
+	return c.do(req, path)
+}
+
+// StreamResponse is a response whose body is read by the caller, so large
+// payloads can be processed without buffering them. Body must be closed.
+type StreamResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       io.ReadCloser
+	Method     string
+	Path       string
+}
+
+// GetStream is like Get but hands the response body over as a stream. Non-2xx
+// responses are read fully and returned as an *APIError.
+func (c *Client) GetStream(path string, token string) (*StreamResponse, error) {
+	req, _ := http.NewRequest(http.MethodGet, c.url(path), nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	// The overall client timeout would cut long downloads short; rely on the
+	// transport's connection/header timeouts instead.
+	hc := *c.client
+	hc.Timeout = 0
+	res, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		defer res.Body.Close()
+		b, _ := io.ReadAll(res.Body)
+		return nil, &APIError{Method: req.Method, Path: path, StatusCode: res.StatusCode, Body: b}
+	}
+	return &StreamResponse{
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+		Body:       res.Body,
+		Method:     req.Method,
+		Path:       path,
+	}, nil
 }
 
 func (c *Client) PostJSON(path string, token string, body any) (*HTTPResponse, error) {
+	return c.sendJSON(http.MethodPost, path, token, body)
+}
+
+// PutJSON replaces the resource at path with body.
+func (c *Client) PutJSON(path string, token string, body any) (*HTTPResponse, error) {
+	return c.sendJSON(http.MethodPut, path, token, body)
+}
+
+// PatchJSON partially updates the resource at path with the fields in body.
+func (c *Client) PatchJSON(path string, token string, body any) (*HTTPResponse, error) {
+	return c.sendJSON(http.MethodPatch, path, token, body)
+}
+
+func (c *Client) sendJSON(method, path string, token string, body any) (*HTTPResponse, error) {
 	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest(http.MethodPost, c.url(path), bytes.NewReader(b))
+	req, _ := http.NewRequest(method, c.url(path), bytes.NewReader(b))
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	return c.do(req)
+	return c.do(req, path)
 }
 
 func (c *Client) Delete(path string, token string) (*HTTPResponse, error) {
@@ -114,7 +189,7 @@ func (c *Client) Delete(path string, token string) (*HTTPResponse, error) {
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
-	return c.do(req)
+	return c.do(req, path)
 }
 
 // Login posts username/password to /auth/login and returns access token and expiry.
@@ -125,8 +200,8 @@ func (c *Client) Login(username, password string) (token string, expiresAt *time
 	if err != nil {
 		return "", nil, err
 	}
-	if res.StatusCode != http.StatusOK {
-		return "", nil, fmt.Errorf("login failed: %d %s", res.StatusCode, string(res.Body))
+	if err := res.Err(); err != nil {
+		return "", nil, fmt.Errorf("login failed: %w", err)
 	}
 	var data struct {
 		AccessToken string `json:"access_token"`
@@ -173,6 +248,38 @@ func parseJWTExp(tok string) (time.Time, error) {
 	return time.Unix(claims.Exp, 0), nil
 }
 
+// TokenSubject returns the user named in a JWT ("sub", or "username"), without
+// verification. It returns "" when the token carries no such claim.
+func TokenSubject(tok string) string {
+	parts := strings.Split(tok, ".")
+	if len(parts) < 2 {
+		return ""
+	}
+	payloadB, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Sub      any    `json:"sub"`
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(payloadB, &claims); err != nil {
+		return ""
+	}
+	if claims.Username != "" {
+		return claims.Username
+	}
+	if claims.Sub != nil {
+		return fmt.Sprint(claims.Sub)
+	}
+	return ""
+}
+
+// APIBase returns the configured API base URL.
+func (c *Client) APIBase() string {
+	return c.cfg.APIBase
+}
+
 func (c *Client) SaveToken(token string, exp *time.Time) error {
 	rec := securestore.Record{AccessToken: token}
 	if exp != nil {
@@ -196,14 +303,72 @@ func (c *Client) LoadToken() (string, *time.Time, error) {
 }
 
 // GetToken returns a valid token or an error instructing to login first.
+// With RememberCredentials set, an expired or soon-to-expire token is renewed
+// silently from the credentials stored in the OS keychain.
 func (c *Client) GetToken() (string, error) {
-	token, _, err := c.LoadToken()
+	token, exp, err := c.LoadToken()
+	if c.cfg.RememberCredentials && (err != nil || expiresWithin(exp, c.cfg.TokenRefreshWindow)) {
+		fresh, rerr := c.relogin()
+		if rerr == nil {
+			return fresh, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("no valid token found and automatic re-login failed (%v). Please run: projet-iac-cli login", rerr)
+		}
+		// Token is still valid for a little while: keep using it.
+	}
 	if err != nil {
 		return "", errors.New("no valid token found. Please run: projet-iac-cli login")
 	}
 	return token, nil
 }
 
+func expiresWithin(exp *time.Time, window time.Duration) bool {
+	if exp == nil || exp.IsZero() {
+		return false
+	}
+	return time.Until(*exp) <= window
+}
+
+// relogin logs in again with the stored credentials and caches the new token.
+func (c *Client) relogin() (string, error) {
+	if !c.usingSecret {
+		return "", errors.New("OS keychain not in use")
+	}
+	creds, err := securestore.LoadCredentials(securestore.CredentialKeyFor(c.cfg.APIBase))
+	if err != nil {
+		return "", fmt.Errorf("no stored credentials: %w", err)
+	}
+	token, exp, err := c.Login(creds.Username, creds.Password)
+	if err != nil {
+		return "", err
+	}
+	if err := c.SaveToken(token, exp); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
 func (c *Client) DeleteToken() error {
 	return c.tokenStore.Delete()
 }
+
+// SaveCredentials stores username/password for automatic re-login. It refuses
+// to do so unless the OS keychain backend is in use.
+func (c *Client) SaveCredentials(username, password string) error {
+	if !c.usingSecret {
+		return errors.New("remember_credentials requires the OS keychain (credentials are never stored in the token file)")
+	}
+	return securestore.SaveCredentials(securestore.CredentialKeyFor(c.cfg.APIBase), securestore.Credentials{
+		Username: username,
+		Password: password,
+	})
+}
+
+// DeleteCredentials removes any credentials stored for automatic re-login.
+func (c *Client) DeleteCredentials() error {
+	if !c.usingSecret {
+		return nil
+	}
+	return securestore.DeleteCredentials(securestore.CredentialKeyFor(c.cfg.APIBase))
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is a non-2xx response from the API.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message())
+}
+
+// Message returns the server's explanation ("detail", "message" or "error"
+// field), the raw body, or the HTTP status text.
+func (e *APIError) Message() string {
+	if m, ok := e.Details().(map[string]any); ok {
+		for _, k := range []string{"detail", "message", "error"} {
+			switch v := m[k].(type) {
+			case string:
+				return v
+			case nil:
+			default:
+				b, _ := json.Marshal(v)
+				return string(b)
+			}
+		}
+	}
+	if s := strings.TrimSpace(string(e.Body)); s != "" {
+		return s
+	}
+	return http.StatusText(e.StatusCode)
+}
+
+// Details returns the decoded JSON body, or nil if the body is not JSON.
+func (e *APIError) Details() any {
+	var v any
+	if err := json.Unmarshal(e.Body, &v); err != nil {
+		return nil
+	}
+	return v
+}
+
+// Code returns a stable, machine-friendly error code for the status.
+func (e *APIError) Code() string {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return "bad_request"
+	case e.StatusCode == http.StatusUnauthorized:
+		return "unauthorized"
+	case e.StatusCode == http.StatusForbidden:
+		return "forbidden"
+	case e.StatusCode == http.StatusNotFound:
+		return "not_found"
+	case e.StatusCode == http.StatusConflict:
+		return "conflict"
+	case e.StatusCode == http.StatusUnprocessableEntity:
+		return "validation_error"
+	case e.StatusCode >= 500:
+		return "server_error"
+	default:
+		return "api_error"
+	}
+}
+
+// Err returns an *APIError for non-2xx responses and nil otherwise.
+func (r *HTTPResponse) Err() error {
+	if r.StatusCode >= 200 && r.StatusCode < 300 {
+		return nil
+	}
+	return &APIError{Method: r.Method, Path: r.Path, StatusCode: r.StatusCode, Body: r.Body}
+}
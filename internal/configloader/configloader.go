@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/Jeomhps/projet-iac-cli/internal/rewrite"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,9 +15,25 @@ type FileConfig struct {
 	VerifyTLS             *bool   `yaml:"verify_tls"`
 	TokenFile             *string `yaml:"token_file"`
 	RewriteLocalhost      *bool   `yaml:"rewrite_localhost"`
-	DockerHostGatewayName *string `yaml:"docker_host_gateway_name"`
-	KeychainMode          *string `yaml:"keychain"` // "auto" | "on" | "off"
-	ColorMode             *string `yaml:"color"`    // "auto" | "always" | "never"
+	DockerHostGatewayName *string `yaml:"docker_host_gateway_name"` // name, or "auto"
+	DockerHost            *string `yaml:"docker_host"`              // alias of docker_host_gateway_name
+	KeychainMode          *string `yaml:"keychain"`                 // "auto" | "on" | "off"
+	ColorMode             *string `yaml:"color"`                    // "auto" | "always" | "never"
+	Output                *string `yaml:"output"`                   // default -o format
+	TimeZone              *string `yaml:"timezone"`                 // IANA zone for displayed timestamps
+
+	// Opt-in automatic re-login (credentials kept in the OS keychain only)
+	RememberCredentials *bool   `yaml:"remember_credentials"`
+	TokenRefreshWindow  *string `yaml:"token_refresh_window"` // Go duration, e.g. "5m"
+
+	AuditLog *string `yaml:"audit_log"` // "" disables the audit log
+
+	// HostRewrites replaces the rewrite rules of the level below when present.
+	HostRewrites []rewrite.Rule `yaml:"host_rewrites"`
+
+	// Profile selects an entry of Profiles; its fields override the top level.
+	Profile  *string               `yaml:"profile"`
+	Profiles map[string]FileConfig `yaml:"profiles"`
 }
 
 // DefaultPath returns ~/.projet-iac/config.yaml
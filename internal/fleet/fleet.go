@@ -0,0 +1,315 @@
+package fleet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/labels"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
+)
+
+// CordonLabel marks a machine cordoned on servers without a cordoned field.
+const CordonLabel = "cordoned"
+
+// Machine is a machine with its reservation state, joined from the machine
+// and reservation lists.
+type Machine struct {
+	Name     string
+	Labels   map[string]string
+	Reserved bool
+	Cordoned bool
+	User     string
+	Until    time.Time // zero when unknown
+}
+
+// Count is the free/reserved split of a set of machines. Cordoned machines
+// are never free; they also count as reserved while a reservation runs.
+type Count struct {
+	Scope    string `json:"scope"` // "all" or key=value
+	Total    int    `json:"total"`
+	Free     int    `json:"free"`
+	Reserved int    `json:"reserved"`
+	Cordoned int    `json:"cordoned"`
+}
+
+// Holding is what one user has reserved.
+type Holding struct {
+	User        string     `json:"user"`
+	Count       int        `json:"count"`
+	Machines    []string   `json:"machines"`
+	NextRelease *time.Time `json:"next_release,omitempty"`
+}
+
+// Release is a point in time when machines become free.
+type Release struct {
+	At        time.Time `json:"at"`
+	Count     int       `json:"count"`
+	Machines  []string  `json:"machines"`
+	FreeAfter int       `json:"free_after"`
+}
+
+// Summary is the fleet utilisation report.
+type Summary struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	WindowHours float64   `json:"window_hours"`
+	Counts      []Count   `json:"counts"` // "all" first, then per label value
+	Users       []Holding `json:"users"`
+	Timeline    []Release `json:"timeline"`
+	// Reserved machines without a release time in the window (later,
+	// overdue or unknown).
+	NotInWindow int `json:"not_in_window"`
+}
+
+// Join builds machines from the /machines and /reservations responses.
+// Active reservations win over the machine's own reserved/reserved_by
+// fields; those that ended before now or are not active are ignored. When
+// the reservations list is available (non-nil), it is the reference: a
+// machine without an active reservation is free, whatever stale fields it
+// still carries. A nil list leaves the machine fields as they are, and so
+// does a list whose reservations do not name their machines.
+func Join(machineItems, reservationItems []any, now time.Time) []Machine {
+	var out []Machine
+	index := map[string]int{}
+	for _, it := range machineItems {
+		m, ok := it.(map[string]any)
+		if !ok {
+			continue
+		}
+		mc := Machine{Name: str(m, "name"), Labels: labels.FromAny(m["labels"])}
+		mc.User = str(m, "reserved_by", "reservation.username", "reservation.user")
+		mc.Until = timeOf(m, "reserved_until", "reservation.expires_at", "expires_at")
+		if b, ok := m["reserved"].(bool); ok {
+			mc.Reserved = b
+		}
+		if mc.User != "" {
+			mc.Reserved = true
+		}
+		mc.Cordoned = Cordoned(m)
+		index[mc.Name] = len(out)
+		out = append(out, mc)
+	}
+
+	held := map[string]bool{}
+	authoritative := reservationItems != nil
+	for _, it := range reservationItems {
+		r, ok := it.(map[string]any)
+		if !ok {
+			continue
+		}
+		user := str(r, "username", "user")
+		until := timeOf(r, "expires_at", "reserved_until", "until")
+		if !active(r, until, now) {
+			continue
+		}
+		names := reservedNames(r)
+		if len(names) == 0 {
+			authoritative = false
+		}
+		for _, name := range names {
+			held[name] = true
+			i, ok := index[name]
+			if !ok {
+				continue
+			}
+			out[i].Reserved = true
+			if user != "" {
+				out[i].User = user
+			}
+			if !until.IsZero() {
+				out[i].Until = until
+			}
+		}
+	}
+	if authoritative {
+		for i := range out {
+			if !held[out[i].Name] {
+				out[i].Reserved, out[i].User, out[i].Until = false, "", time.Time{}
+			}
+		}
+	}
+	return out
+}
+
+// Cordoned reports whether a machine object is cordoned: its cordoned field
+// when the server has one, the CordonLabel label otherwise.
+func Cordoned(m map[string]any) bool {
+	if b, ok := m["cordoned"].(bool); ok {
+		return b
+	}
+	return labels.FromAny(m["labels"])[CordonLabel] == "true"
+}
+
+// Summarize reports counts (per value of labelKey too, when set), holdings
+// per user, and the releases between now and now+window.
+func Summarize(ms []Machine, now time.Time, window time.Duration, labelKey string) Summary {
+	s := Summary{GeneratedAt: now.UTC(), WindowHours: window.Hours()}
+
+	all := Count{Scope: "all"}
+	perLabel := map[string]*Count{}
+	holdings := map[string]*Holding{}
+	releases := map[time.Time][]Machine{}
+
+	for _, m := range ms {
+		add(&all, m)
+		if labelKey != "" {
+			scope := labelKey + "=" + m.Labels[labelKey]
+			if _, ok := m.Labels[labelKey]; !ok {
+				scope = "!" + labelKey
+			}
+			c, ok := perLabel[scope]
+			if !ok {
+				c = &Count{Scope: scope}
+				perLabel[scope] = c
+			}
+			add(c, m)
+		}
+		if !m.Reserved {
+			continue
+		}
+
+		user := m.User
+		if user == "" {
+			user = "(unknown)"
+		}
+		h, ok := holdings[user]
+		if !ok {
+			h = &Holding{User: user}
+			holdings[user] = h
+		}
+		h.Count++
+		h.Machines = append(h.Machines, m.Name)
+		if !m.Until.IsZero() && m.Until.After(now) && (h.NextRelease == nil || m.Until.Before(*h.NextRelease)) {
+			t := m.Until.UTC()
+			h.NextRelease = &t
+		}
+
+		if !m.Until.IsZero() && m.Until.After(now) && !m.Until.After(now.Add(window)) {
+			t := m.Until.UTC()
+			releases[t] = append(releases[t], m)
+		} else {
+			s.NotInWindow++
+		}
+	}
+
+	s.Counts = append(s.Counts, all)
+	scopes := make([]string, 0, len(perLabel))
+	for k := range perLabel {
+		scopes = append(scopes, k)
+	}
+	sort.Strings(scopes)
+	for _, k := range scopes {
+		s.Counts = append(s.Counts, *perLabel[k])
+	}
+
+	s.Users = []Holding{}
+	for _, h := range holdings {
+		sort.Strings(h.Machines)
+		s.Users = append(s.Users, *h)
+	}
+	sort.Slice(s.Users, func(i, j int) bool {
+		if s.Users[i].Count != s.Users[j].Count {
+			return s.Users[i].Count > s.Users[j].Count
+		}
+		return s.Users[i].User < s.Users[j].User
+	})
+
+	s.Timeline = []Release{}
+	free := all.Free
+	times := make([]time.Time, 0, len(releases))
+	for t := range releases {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	for _, t := range times {
+		var names []string
+		for _, m := range releases[t] {
+			names = append(names, m.Name)
+			if !m.Cordoned {
+				free++
+			}
+		}
+		sort.Strings(names)
+		s.Timeline = append(s.Timeline, Release{At: t, Count: len(names), Machines: names, FreeAfter: free})
+	}
+	return s
+}
+
+func add(c *Count, m Machine) {
+	c.Total++
+	if m.Cordoned {
+		c.Cordoned++
+	}
+	if m.Reserved {
+		c.Reserved++
+	} else if !m.Cordoned {
+		c.Free++
+	}
+}
+
+// active reports whether a reservation still holds its machines: not past
+// its end time, and not marked otherwise by a status or active field.
+func active(r map[string]any, until, now time.Time) bool {
+	if !until.IsZero() && !until.After(now) {
+		return false
+	}
+	if b, ok := r["active"].(bool); ok && !b {
+		return false
+	}
+	if st, ok := r["status"].(string); ok && st != "" && !strings.EqualFold(st, "active") {
+		return false
+	}
+	return true
+}
+
+// reservedNames lists the machine names of a reservation, whichever shape
+// the API uses.
+func reservedNames(r map[string]any) []string {
+	var names []string
+	for _, key := range []string{"machines", "machine_names", "machine", "machine_name"} {
+		switch v := r[key].(type) {
+		case string:
+			names = append(names, v)
+		case map[string]any:
+			if n, ok := v["name"].(string); ok {
+				names = append(names, n)
+			}
+		case []any:
+			for _, el := range v {
+				switch e := el.(type) {
+				case string:
+					names = append(names, e)
+				case map[string]any:
+					if n, ok := e["name"].(string); ok {
+						names = append(names, n)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+func str(m map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if v, ok := output.Lookup(m, k); ok && v != nil {
+			if s := fmt.Sprint(v); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func timeOf(m map[string]any, keys ...string) time.Time {
+	for _, k := range keys {
+		v, _ := output.Lookup(m, k)
+		if s, ok := v.(string); ok {
+			if t, ok := output.ParseTime(s); ok {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
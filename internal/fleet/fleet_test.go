@@ -0,0 +1,172 @@
+package fleet
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func at(d time.Duration) string { return now.Add(d).Format(time.RFC3339) }
+
+func machine(name string, fields map[string]any) map[string]any {
+	m := map[string]any{"name": name}
+	for k, v := range fields {
+		m[k] = v
+	}
+	return m
+}
+
+func TestJoin(t *testing.T) {
+	machines := []any{
+		machine("free", nil),
+		machine("own-fields", map[string]any{"reserved": true, "reserved_by": "alice", "reserved_until": at(time.Hour)}),
+		machine("stale", map[string]any{"reserved": true, "reserved_by": "bob", "reserved_until": at(-time.Hour)}),
+		machine("by-list", nil),
+		machine("nested", nil),
+		machine("cordoned-field", map[string]any{"cordoned": true, "labels": map[string]any{"os": "alpine"}}),
+		machine("cordoned-label", map[string]any{"labels": map[string]any{CordonLabel: "true"}}),
+		machine("uncordoned-field", map[string]any{"cordoned": false, "labels": map[string]any{CordonLabel: "true"}}),
+		"not an object",
+	}
+	reservations := []any{
+		map[string]any{"username": "alice", "expires_at": at(2 * time.Hour), "machines": []any{map[string]any{"name": "own-fields"}}},
+		map[string]any{"user": "carol", "expires_at": at(30 * time.Minute), "machine_names": []any{"by-list", "unknown"}},
+		map[string]any{"username": "dave", "reserved_until": at(time.Hour), "machine": map[string]any{"name": "nested"}},
+		map[string]any{"username": "erin", "expires_at": at(-time.Minute), "machines": []any{"free"}},
+		map[string]any{"username": "frank", "expires_at": at(time.Hour), "status": "released", "machines": []any{"free"}},
+		map[string]any{"username": "gina", "expires_at": at(time.Hour), "active": false, "machines": []any{"free"}},
+	}
+
+	got := Join(machines, reservations, now)
+	want := []Machine{
+		{Name: "free"},
+		{Name: "own-fields", Reserved: true, User: "alice", Until: now.Add(2 * time.Hour)},
+		{Name: "stale"},
+		{Name: "by-list", Reserved: true, User: "carol", Until: now.Add(30 * time.Minute)},
+		{Name: "nested", Reserved: true, User: "dave", Until: now.Add(time.Hour)},
+		{Name: "cordoned-field", Labels: map[string]string{"os": "alpine"}, Cordoned: true},
+		{Name: "cordoned-label", Labels: map[string]string{CordonLabel: "true"}, Cordoned: true},
+		{Name: "uncordoned-field", Labels: map[string]string{CordonLabel: "true"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Join:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestJoinWithoutReservations(t *testing.T) {
+	machines := []any{
+		machine("stale", map[string]any{"reserved": true, "reserved_by": "bob", "reserved_until": at(-time.Hour)}),
+		machine("nested", map[string]any{"reservation": map[string]any{"username": "carol", "expires_at": at(time.Hour)}}),
+		machine("flag-only", map[string]any{"reserved": true}),
+	}
+	want := []Machine{
+		{Name: "stale", Reserved: true, User: "bob", Until: now.Add(-time.Hour)},
+		{Name: "nested", Reserved: true, User: "carol", Until: now.Add(time.Hour)},
+		{Name: "flag-only", Reserved: true},
+	}
+	// No list: the machine fields are all there is.
+	if got := Join(machines, nil, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("Join(nil):\n got %+v\nwant %+v", got, want)
+	}
+	// An empty list: nothing is reserved.
+	for _, m := range Join(machines, []any{}, now) {
+		if m.Reserved || m.User != "" || !m.Until.IsZero() {
+			t.Errorf("Join(empty): %+v still reserved", m)
+		}
+	}
+	// Reservations without machine names cannot tell which machines are
+	// free, so the machine fields stay.
+	byID := []any{map[string]any{"username": "bob", "expires_at": at(time.Hour), "machine_ids": []any{1.0}}}
+	if got := Join(machines, byID, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("Join(by id):\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	ms := []Machine{
+		{Name: "a1", Labels: map[string]string{"os": "alpine"}},
+		{Name: "a2", Labels: map[string]string{"os": "alpine"}, Reserved: true, User: "alice", Until: now.Add(time.Hour)},
+		{Name: "a3", Labels: map[string]string{"os": "alpine"}, Reserved: true, User: "alice", Until: now.Add(time.Hour)},
+		{Name: "d1", Labels: map[string]string{"os": "debian"}, Reserved: true, User: "bob", Until: now.Add(3 * time.Hour)},
+		// Cordoned and reserved: counted as both, never free, even after release.
+		{Name: "d2", Labels: map[string]string{"os": "debian"}, Cordoned: true, Reserved: true, User: "bob", Until: now.Add(2 * time.Hour)},
+		{Name: "c1", Cordoned: true},
+		// Window edges: ending now is overdue, ending at now+window is in.
+		{Name: "edge-now", Reserved: true, User: "carol", Until: now},
+		{Name: "edge-end", Reserved: true, User: "carol", Until: now.Add(4 * time.Hour)},
+		{Name: "later", Reserved: true, User: "carol", Until: now.Add(4*time.Hour + time.Second)},
+		{Name: "no-end", Reserved: true},
+	}
+	s := Summarize(ms, now, 4*time.Hour, "os")
+
+	if s.WindowHours != 4 || !s.GeneratedAt.Equal(now) {
+		t.Errorf("window %v at %v", s.WindowHours, s.GeneratedAt)
+	}
+	wantCounts := []Count{
+		{Scope: "all", Total: 10, Free: 1, Reserved: 8, Cordoned: 2},
+		{Scope: "!os", Total: 5, Free: 0, Reserved: 4, Cordoned: 1},
+		{Scope: "os=alpine", Total: 3, Free: 1, Reserved: 2},
+		{Scope: "os=debian", Total: 2, Free: 0, Reserved: 2, Cordoned: 1},
+	}
+	if !reflect.DeepEqual(s.Counts, wantCounts) {
+		t.Errorf("counts:\n got %+v\nwant %+v", s.Counts, wantCounts)
+	}
+
+	release := func(d time.Duration) *time.Time { t := now.Add(d); return &t }
+	wantUsers := []Holding{
+		{User: "carol", Count: 3, Machines: []string{"edge-end", "edge-now", "later"}, NextRelease: release(4 * time.Hour)},
+		{User: "alice", Count: 2, Machines: []string{"a2", "a3"}, NextRelease: release(time.Hour)},
+		{User: "bob", Count: 2, Machines: []string{"d1", "d2"}, NextRelease: release(2 * time.Hour)},
+		{User: "(unknown)", Count: 1, Machines: []string{"no-end"}},
+	}
+	if !reflect.DeepEqual(s.Users, wantUsers) {
+		t.Errorf("users:\n got %+v\nwant %+v", s.Users, wantUsers)
+	}
+
+	// Free after each release: 1 now, +2 (a2, a3), +0 (d2 stays cordoned),
+	// +1 (d1), +1 (edge-end).
+	wantTimeline := []Release{
+		{At: now.Add(time.Hour), Count: 2, Machines: []string{"a2", "a3"}, FreeAfter: 3},
+		{At: now.Add(2 * time.Hour), Count: 1, Machines: []string{"d2"}, FreeAfter: 3},
+		{At: now.Add(3 * time.Hour), Count: 1, Machines: []string{"d1"}, FreeAfter: 4},
+		{At: now.Add(4 * time.Hour), Count: 1, Machines: []string{"edge-end"}, FreeAfter: 5},
+	}
+	if !reflect.DeepEqual(s.Timeline, wantTimeline) {
+		t.Errorf("timeline:\n got %+v\nwant %+v", s.Timeline, wantTimeline)
+	}
+	// edge-now (overdue), later (past the window) and no-end.
+	if s.NotInWindow != 3 {
+		t.Errorf("NotInWindow = %d, want 3", s.NotInWindow)
+	}
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	s := Summarize(nil, now, time.Hour, "")
+	if want := []Count{{Scope: "all"}}; !reflect.DeepEqual(s.Counts, want) {
+		t.Errorf("counts = %+v, want %+v", s.Counts, want)
+	}
+	if s.Users == nil || s.Timeline == nil || len(s.Users) != 0 || len(s.Timeline) != 0 {
+		t.Errorf("users %#v, timeline %#v, want empty non-nil lists", s.Users, s.Timeline)
+	}
+}
+
+func TestCordoned(t *testing.T) {
+	tests := []struct {
+		m    map[string]any
+		want bool
+	}{
+		{map[string]any{}, false},
+		{map[string]any{"cordoned": true}, true},
+		{map[string]any{"cordoned": false, "labels": map[string]any{CordonLabel: "true"}}, false},
+		{map[string]any{"labels": map[string]any{CordonLabel: "true"}}, true},
+		{map[string]any{"labels": map[string]any{CordonLabel: true}}, true},
+		{map[string]any{"labels": map[string]any{CordonLabel: "false"}}, false},
+	}
+	for _, tt := range tests {
+		if got := Cordoned(tt.m); got != tt.want {
+			t.Errorf("Cordoned(%v) = %v, want %v", tt.m, got, tt.want)
+		}
+	}
+}
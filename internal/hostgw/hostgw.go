@@ -0,0 +1,272 @@
+package hostgw
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Container runtimes.
+const (
+	Docker  = "docker"
+	Podman  = "podman"
+	Unknown = "unknown"
+)
+
+// Names containers use to reach the host when the runtime provides one.
+const (
+	DockerHostName = "host.docker.internal"
+	PodmanHostName = "host.containers.internal"
+)
+
+// Warning is the Check of findings the user should act on.
+const Warning = "warning"
+
+// Finding is one step of the detection, for doctor-style explanations.
+type Finding struct {
+	Check  string `json:"check"`
+	Value  string `json:"value"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Result is the detected runtime and the host name or address containers
+// should use to reach this host.
+type Result struct {
+	Runtime  string    `json:"runtime"`
+	Target   string    `json:"target"`
+	Bridge   string    `json:"bridge,omitempty"` // docker0 address, if any
+	Findings []Finding `json:"findings"`
+}
+
+// Detector inspects the local machine. The zero value looks at the real
+// system; fields can be replaced to run against fixtures.
+type Detector struct {
+	Root       string                                 // filesystem root, "" means /
+	GOOS       string                                 // "" means runtime.GOOS
+	Getenv     func(string) string                    // nil means os.Getenv
+	HomeDir    string                                 // "" means os.UserHomeDir
+	Interfaces func() (map[string][]net.IPNet, error) // nil means net.Interfaces
+}
+
+// Detect works out the container runtime and the gateway name:
+//   - Podman provides host.containers.internal;
+//   - Docker Desktop (macOS, Windows) provides host.docker.internal;
+//   - Docker on Linux only resolves host.docker.internal with extra_hosts,
+//     so the docker0 bridge address is used when there is one.
+func (d Detector) Detect() Result {
+	r := Result{Runtime: Unknown}
+	r.Runtime = d.runtime(&r)
+
+	switch {
+	case r.Runtime == Podman:
+		r.Target = PodmanHostName
+		r.add("gateway", r.Target, "Podman adds host.containers.internal to every container")
+	case d.goos() != "linux":
+		r.Target = DockerHostName
+		r.add("gateway", r.Target, "Docker Desktop on "+d.goos()+" resolves host.docker.internal")
+	default:
+		bridge, detail, unsure := d.bridge()
+		r.add("docker0", orNone(bridge), detail)
+		if unsure {
+			r.add(Warning, DockerHostName, "docker0 is routed but its address could not be read; not guessing it, set docker_host explicitly if host.docker.internal does not resolve in the API containers")
+		}
+		if bridge != "" {
+			r.Bridge = bridge
+			r.Target = bridge
+			r.add("gateway", r.Target, "Linux: host.docker.internal needs extra_hosts in the API containers; the docker0 address works without it")
+		} else {
+			r.Target = DockerHostName
+			why := "no docker0 bridge (Docker Desktop, rootless or no Docker)"
+			if unsure {
+				why = "docker0 address unknown"
+			}
+			r.add("gateway", r.Target, why+": keeping host.docker.internal; Linux containers need extra_hosts: [\"host.docker.internal:host-gateway\"]")
+		}
+	}
+	return r
+}
+
+func (r *Result) add(check, value, detail string) {
+	r.Findings = append(r.Findings, Finding{Check: check, Value: value, Detail: detail})
+}
+
+// runtime guesses docker or podman from the environment, sockets and
+// config files.
+func (d Detector) runtime(r *Result) string {
+	getenv := d.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	if h := getenv("CONTAINER_HOST"); h != "" {
+		r.add("runtime", Podman, "CONTAINER_HOST="+h)
+		return Podman
+	}
+	if h := getenv("DOCKER_HOST"); h != "" {
+		if strings.Contains(h, "podman") {
+			r.add("runtime", Podman, "DOCKER_HOST points at a Podman socket ("+h+")")
+			return Podman
+		}
+		r.add("runtime", Docker, "DOCKER_HOST="+h)
+		return Docker
+	}
+
+	sock := d.path("/var/run/docker.sock")
+	if target, err := filepath.EvalSymlinks(sock); err == nil {
+		if strings.Contains(target, "podman") {
+			r.add("runtime", Podman, sock+" is a link to "+target)
+			return Podman
+		}
+		r.add("runtime", Docker, sock+" exists")
+		return Docker
+	}
+
+	home := d.home()
+	podmanFiles := []string{
+		d.path("/run/podman/podman.sock"),
+		d.path("/etc/containers/containers.conf"),
+	}
+	if xdg := getenv("XDG_RUNTIME_DIR"); xdg != "" {
+		podmanFiles = append(podmanFiles, filepath.Join(d.path(xdg), "podman", "podman.sock"))
+	}
+	if home != "" {
+		podmanFiles = append(podmanFiles, filepath.Join(home, ".config", "containers", "containers.conf"))
+	}
+	dockerFiles := []string{d.path("/etc/docker/daemon.json")}
+	if home != "" {
+		dockerFiles = append(dockerFiles, filepath.Join(home, ".docker", "config.json"))
+	}
+
+	if f := firstExisting(podmanFiles); f != "" {
+		r.add("runtime", Podman, f+" exists")
+		return Podman
+	}
+	if f := firstExisting(dockerFiles); f != "" {
+		r.add("runtime", Docker, f+" exists")
+		return Docker
+	}
+	r.add("runtime", Unknown, "no Docker or Podman socket or config found; assuming Docker")
+	return Unknown
+}
+
+// bridge finds the host address on docker0: the route table gives the
+// bridge subnet, and the interface address in that subnet is the host side.
+// unsure is set when the subnet exists but the address cannot be read.
+func (d Detector) bridge() (addr, detail string, unsure bool) {
+	subnet, err := d.routeSubnet("docker0")
+	if err != nil {
+		return "", err.Error(), false
+	}
+	ifaces := d.Interfaces
+	if ifaces == nil {
+		ifaces = systemInterfaces
+	}
+	addrs, err := ifaces()
+	if err != nil {
+		return "", "route " + subnet.String() + ", but interfaces not readable: " + err.Error(), true
+	}
+	for _, a := range addrs["docker0"] {
+		if ip4 := a.IP.To4(); ip4 != nil && subnet.Contains(ip4) {
+			return ip4.String(), "docker0 address in route " + subnet.String(), false
+		}
+	}
+	return "", "route " + subnet.String() + ", but no docker0 address in it", true
+}
+
+// routeSubnet reads /proc/net/route for the subnet routed through iface.
+func (d Detector) routeSubnet(iface string) (*net.IPNet, error) {
+	f, err := os.Open(d.path("/proc/net/route"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read routes: %v", err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// Iface Destination Gateway Flags RefCnt Use Metric Mask ...
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 8 || fields[0] != iface {
+			continue
+		}
+		dst, err1 := hexIPv4(fields[1])
+		mask, err2 := hexIPv4(fields[7])
+		if err1 != nil || err2 != nil || dst.IsUnspecified() {
+			continue // also skips a default route through iface
+		}
+		return &net.IPNet{IP: dst, Mask: net.IPMask(mask)}, nil
+	}
+	return nil, fmt.Errorf("no route via %s", iface)
+}
+
+// hexIPv4 decodes the little-endian hex addresses of /proc/net/route.
+func hexIPv4(s string) (net.IP, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 4 {
+		return nil, fmt.Errorf("bad address %q", s)
+	}
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, binary.LittleEndian.Uint32(b))
+	return ip, nil
+}
+
+func systemInterfaces() (map[string][]net.IPNet, error) {
+	ifs, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	out := map[string][]net.IPNet{}
+	for _, i := range ifs {
+		addrs, err := i.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if n, ok := a.(*net.IPNet); ok {
+				out[i.Name] = append(out[i.Name], *n)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (d Detector) path(p string) string {
+	if d.Root == "" {
+		return p
+	}
+	return filepath.Join(d.Root, p)
+}
+
+func (d Detector) goos() string {
+	if d.GOOS != "" {
+		return d.GOOS
+	}
+	return runtime.GOOS
+}
+
+func (d Detector) home() string {
+	if d.HomeDir != "" {
+		return d.HomeDir
+	}
+	h, _ := os.UserHomeDir()
+	return h
+}
+
+func firstExisting(paths []string) string {
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,168 @@
+package hostgw
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// docker0Route routes 172.17.0.0/16 through docker0, in /proc/net/route's
+// little-endian hex.
+const docker0Route = "Iface\tDestination\tGateway\tFlags\tRefCnt\tUse\tMetric\tMask\tMTU\tWindow\tIRTT\n" +
+	"eth0\t00000000\t0102A8C0\t0003\t0\t0\t0\t00000000\t0\t0\t0\n" +
+	"docker0\t000011AC\t00000000\t0001\t0\t0\t0\t0000FFFF\t0\t0\t0\n"
+
+func TestDetect(t *testing.T) {
+	docker0 := func() (map[string][]net.IPNet, error) {
+		return map[string][]net.IPNet{
+			"docker0": {{IP: net.IPv4(172, 17, 0, 1), Mask: net.CIDRMask(16, 32)}},
+		}, nil
+	}
+	noDocker0 := func() (map[string][]net.IPNet, error) {
+		return map[string][]net.IPNet{}, nil
+	}
+
+	tests := []struct {
+		name        string
+		goos        string
+		env         map[string]string
+		files       map[string]string // path under root -> content
+		links       map[string]string // path under root -> target under root
+		interfaces  func() (map[string][]net.IPNet, error)
+		wantRuntime string
+		wantTarget  string
+		wantBridge  string
+		wantWarning bool
+	}{
+		{
+			name:        "podman from CONTAINER_HOST",
+			goos:        "linux",
+			env:         map[string]string{"CONTAINER_HOST": "unix:///run/user/1000/podman/podman.sock"},
+			wantRuntime: Podman,
+			wantTarget:  PodmanHostName,
+		},
+		{
+			name:        "podman behind docker.sock",
+			goos:        "linux",
+			files:       map[string]string{"/run/podman/podman.sock": ""},
+			links:       map[string]string{"/var/run/docker.sock": "/run/podman/podman.sock"},
+			wantRuntime: Podman,
+			wantTarget:  PodmanHostName,
+		},
+		{
+			name:        "docker desktop",
+			goos:        "darwin",
+			files:       map[string]string{"/var/run/docker.sock": ""},
+			wantRuntime: Docker,
+			wantTarget:  DockerHostName,
+		},
+		{
+			name:        "linux docker0 bridge",
+			goos:        "linux",
+			files:       map[string]string{"/var/run/docker.sock": "", "/proc/net/route": docker0Route},
+			interfaces:  docker0,
+			wantRuntime: Docker,
+			wantTarget:  "172.17.0.1",
+			wantBridge:  "172.17.0.1",
+		},
+		{
+			name:        "linux docker0 address unreadable",
+			goos:        "linux",
+			files:       map[string]string{"/var/run/docker.sock": "", "/proc/net/route": docker0Route},
+			interfaces:  noDocker0,
+			wantRuntime: Docker,
+			wantTarget:  DockerHostName,
+			wantWarning: true,
+		},
+		{
+			name:        "linux without docker0",
+			goos:        "linux",
+			env:         map[string]string{"DOCKER_HOST": "unix:///var/run/docker.sock"},
+			files:       map[string]string{"/proc/net/route": "Iface\tDestination\n"},
+			interfaces:  docker0,
+			wantRuntime: Docker,
+			wantTarget:  DockerHostName,
+		},
+		{
+			name:        "nothing installed",
+			goos:        "linux",
+			wantRuntime: Unknown,
+			wantTarget:  DockerHostName,
+		},
+	}
+
+	// Roots are numbered rather than named after the case: the runtime
+	// check looks for "podman" in paths.
+	base := t.TempDir()
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := filepath.Join(base, fmt.Sprint(i))
+			for p, content := range tt.files {
+				writeFixture(t, filepath.Join(root, p), content)
+			}
+			for p, target := range tt.links {
+				link := filepath.Join(root, p)
+				if err := os.MkdirAll(filepath.Dir(link), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink(filepath.Join(root, target), link); err != nil {
+					t.Fatal(err)
+				}
+			}
+			interfaces := tt.interfaces
+			if interfaces == nil {
+				interfaces = noDocker0
+			}
+			d := Detector{
+				Root:       root,
+				GOOS:       tt.goos,
+				Getenv:     func(k string) string { return tt.env[k] },
+				HomeDir:    filepath.Join(root, "home"),
+				Interfaces: interfaces,
+			}
+
+			r := d.Detect()
+			if r.Runtime != tt.wantRuntime {
+				t.Errorf("Runtime = %q, want %q", r.Runtime, tt.wantRuntime)
+			}
+			if r.Target != tt.wantTarget {
+				t.Errorf("Target = %q, want %q", r.Target, tt.wantTarget)
+			}
+			if r.Bridge != tt.wantBridge {
+				t.Errorf("Bridge = %q, want %q", r.Bridge, tt.wantBridge)
+			}
+			var warned bool
+			for _, f := range r.Findings {
+				warned = warned || f.Check == Warning
+			}
+			if warned != tt.wantWarning {
+				t.Errorf("warning = %v, want %v (findings %+v)", warned, tt.wantWarning, r.Findings)
+			}
+		})
+	}
+}
+
+func TestHexIPv4(t *testing.T) {
+	ip, err := hexIPv4("000011AC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ip.String(); got != "172.17.0.0" {
+		t.Errorf("hexIPv4 = %s, want 172.17.0.0", got)
+	}
+	if _, err := hexIPv4("zz"); err == nil {
+		t.Error("hexIPv4(zz) succeeded")
+	}
+}
+
+func writeFixture(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,341 @@
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseAnsibleINI reads an Ansible INI inventory: [group], [group:vars] and
+// [group:children] sections, host lines with key=value variables, and host
+// ranges such as web[01:03].
+func ParseAnsibleINI(data []byte) ([]types.MachineCreate, []Skipped, error) {
+	groups := map[string]*group{"all": newGroup(), "ungrouped": newGroup()}
+	isChild := map[string]bool{}
+	get := func(name string) *group {
+		g, ok := groups[name]
+		if !ok {
+			g = newGroup()
+			groups[name] = g
+		}
+		return g
+	}
+
+	section, kind := "ungrouped", "hosts"
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section, kind = line[1:len(line)-1], "hosts"
+			if i := strings.LastIndex(section, ":"); i >= 0 {
+				section, kind = section[:i], section[i+1:]
+			}
+			if kind != "hosts" && kind != "vars" && kind != "children" {
+				return nil, nil, fmt.Errorf("line %d: unknown section type %q", lineNo, kind)
+			}
+			get(section)
+			continue
+		}
+
+		g := get(section)
+		switch kind {
+		case "vars":
+			k, v, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, nil, fmt.Errorf("line %d: expected key=value in [%s:vars]", lineNo, section)
+			}
+			g.vars[strings.TrimSpace(k)] = unquote(strings.TrimSpace(v))
+		case "children":
+			g.children = append(g.children, line)
+			get(line)
+			isChild[line] = true
+		default:
+			fields, err := splitFields(line)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			vars := map[string]string{}
+			for _, f := range fields[1:] {
+				k, v, ok := strings.Cut(f, "=")
+				if !ok {
+					return nil, nil, fmt.Errorf("line %d: expected key=value, got %q", lineNo, f)
+				}
+				vars[k] = v
+			}
+			names, err := expandRange(fields[0])
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			for _, n := range names {
+				g.addHost(n, vars)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	all := groups["all"]
+	for name := range groups {
+		if name != "all" && !isChild[name] {
+			all.children = append(all.children, name)
+		}
+	}
+	sortChildren(all)
+	machines, skipped := resolve(groups)
+	return machines, skipped, nil
+}
+
+// ParseAnsibleYAML reads an Ansible YAML inventory: nested groups with
+// hosts, vars and children, starting at "all" (or at every top-level group).
+func ParseAnsibleYAML(data []byte) ([]types.MachineCreate, []Skipped, error) {
+	type yamlGroup struct {
+		Hosts    map[string]map[string]any `yaml:"hosts"`
+		Vars     map[string]any            `yaml:"vars"`
+		Children map[string]yaml.Node      `yaml:"children"`
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("parse YAML: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil, nil
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("inventory must be a mapping of groups")
+	}
+
+	groups := map[string]*group{}
+	var add func(name string, node *yaml.Node) error
+	add = func(name string, node *yaml.Node) error {
+		var yg yamlGroup
+		if err := node.Decode(&yg); err != nil {
+			return fmt.Errorf("group %s: %w", name, err)
+		}
+		g, ok := groups[name]
+		if !ok {
+			g = newGroup()
+			groups[name] = g
+		}
+		for k, v := range yg.Vars {
+			g.vars[k] = scalar(v)
+		}
+		for _, h := range orderedKeys(node, "hosts") {
+			vars := map[string]string{}
+			for k, v := range yg.Hosts[h] {
+				vars[k] = scalar(v)
+			}
+			names, err := expandRange(h)
+			if err != nil {
+				return err
+			}
+			for _, n := range names {
+				g.addHost(n, vars)
+			}
+		}
+		for _, c := range orderedKeys(node, "children") {
+			g.children = append(g.children, c)
+			child := yg.Children[c]
+			if err := add(c, &child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		name := doc.Content[i].Value
+		if err := add(name, doc.Content[i+1]); err != nil {
+			return nil, nil, err
+		}
+	}
+	if _, ok := groups["all"]; !ok {
+		all := newGroup()
+		for i := 0; i+1 < len(doc.Content); i += 2 {
+			all.children = append(all.children, doc.Content[i].Value)
+		}
+		groups["all"] = all
+	}
+	machines, skipped := resolve(groups)
+	return machines, skipped, nil
+}
+
+// orderedKeys returns the keys of the mapping under key in node, in file
+// order (maps lose it).
+func orderedKeys(node *yaml.Node, key string) []string {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != key {
+			continue
+		}
+		v := node.Content[i+1]
+		var keys []string
+		for j := 0; j+1 < len(v.Content); j += 2 {
+			keys = append(keys, v.Content[j].Value)
+		}
+		return keys
+	}
+	return nil
+}
+
+func scalar(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// sortChildren puts "ungrouped" first and the other groups in name order, so
+// results do not depend on map iteration.
+func sortChildren(g *group) {
+	rank := func(s string) string {
+		if s == "ungrouped" {
+			return ""
+		}
+		return s
+	}
+	sort.Slice(g.children, func(i, j int) bool {
+		return rank(g.children[i]) < rank(g.children[j])
+	})
+}
+
+// splitFields splits a host line on whitespace, keeping quoted values
+// together and dropping the quotes.
+func splitFields(line string) ([]string, error) {
+	var (
+		fields []string
+		cur    strings.Builder
+		quote  rune
+		inTok  bool
+	)
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inTok = r, true
+		case r == ' ' || r == '\t':
+			if inTok {
+				fields = append(fields, cur.String())
+				cur.Reset()
+				inTok = false
+			}
+		case r == '#' && !inTok:
+			return fields, nil
+		default:
+			cur.WriteRune(r)
+			inTok = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inTok {
+		fields = append(fields, cur.String())
+	}
+	return fields, nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// expandRange expands Ansible host ranges: web[01:03] gives web01, web02,
+// web03; db-[a:c] gives db-a, db-b, db-c. An optional third part is the step.
+func expandRange(pattern string) ([]string, error) {
+	open := strings.Index(pattern, "[")
+	if open < 0 {
+		return []string{pattern}, nil
+	}
+	end := strings.Index(pattern[open:], "]")
+	if end < 0 {
+		return nil, fmt.Errorf("host range %q: missing ]", pattern)
+	}
+	end += open
+	prefix, spec, suffix := pattern[:open], pattern[open+1:end], pattern[end+1:]
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("host range %q: want [start:end] or [start:end:step]", pattern)
+	}
+	step := 1
+	if len(parts) == 3 {
+		n, err := strconv.Atoi(parts[2])
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("host range %q: bad step", pattern)
+		}
+		step = n
+	}
+
+	var items []string
+	from, errFrom := strconv.Atoi(parts[0])
+	to, errTo := strconv.Atoi(parts[1])
+	switch {
+	case errFrom == nil && errTo == nil:
+		if from > to {
+			return nil, fmt.Errorf("host range %q: start after end", pattern)
+		}
+		width := 0
+		if len(parts[0]) > 1 && parts[0][0] == '0' {
+			width = len(parts[0])
+		}
+		for i := from; i <= to; i += step {
+			items = append(items, fmt.Sprintf("%0*d", width, i))
+		}
+	case letterCase(parts[0]) != 0 && letterCase(parts[0]) == letterCase(parts[1]) && parts[0] <= parts[1]:
+		for c := parts[0][0]; c <= parts[1][0]; c += byte(step) {
+			items = append(items, string(c))
+			if int(c)+step > 255 {
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("host range %q: bad bounds", pattern)
+	}
+
+	rest, err := expandRange(suffix)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, it := range items {
+		for _, r := range rest {
+			out = append(out, prefix+it+r)
+		}
+	}
+	return out, nil
+}
+
+// letterCase returns 'a' or 'A' when s is a single lower or upper case ASCII
+// letter, and 0 otherwise. Alphabetic range bounds must share a case.
+func letterCase(s string) byte {
+	switch {
+	case len(s) != 1:
+		return 0
+	case s[0] >= 'a' && s[0] <= 'z':
+		return 'a'
+	case s[0] >= 'A' && s[0] <= 'Z':
+		return 'A'
+	}
+	return 0
+}
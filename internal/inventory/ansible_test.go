@@ -0,0 +1,226 @@
+package inventory
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/types"
+)
+
+const iniInventory = `# comment
+; another comment
+bastion ansible_host=10.0.0.1 ansible_user=admin
+
+[web]
+web[01:03] ansible_port=2222
+db-[a:b].lan   # trailing comment
+
+[web:vars]
+ansible_user=deploy
+ansible_ssh_private_key_file=~/.ssh/web
+
+[prod:children]
+web
+
+[prod:vars]
+ansible_user=produser
+ansible_password="s3cret"
+
+[broken]
+vaulted ansible_password='$ANSIBLE_VAULT;1.1;AES256'
+templated ansible_host="{{ inventory_hostname }}.lan"
+local ansible_connection=local
+badport ansible_port=99999
+`
+
+func TestParseAnsibleINI(t *testing.T) {
+	machines, skipped, err := ParseAnsibleINI([]byte(iniInventory))
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := func(name string, port int) types.MachineCreate {
+		return types.MachineCreate{
+			Name: name, Host: name, Port: port, User: "deploy", Password: "s3cret",
+			PrivateKeyFile: "~/.ssh/web",
+		}
+	}
+	want := []types.MachineCreate{
+		{Name: "bastion", Host: "10.0.0.1", Port: 22, User: "admin"},
+		web("web01", 2222),
+		web("web02", 2222),
+		web("web03", 2222),
+		web("db-a.lan", 22),
+		web("db-b.lan", 22),
+	}
+	if !reflect.DeepEqual(machines, want) {
+		t.Errorf("machines:\n got %+v\nwant %+v", machines, want)
+	}
+	wantSkipped := []Skipped{
+		{Name: "vaulted", Reason: "ansible_password is vault-encrypted"},
+		{Name: "templated", Reason: "ansible_host uses a Jinja template"},
+		{Name: "local", Reason: "ansible_connection=local is not SSH"},
+		{Name: "badport", Reason: `invalid ansible_port "99999"`},
+	}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Errorf("skipped:\n got %+v\nwant %+v", skipped, wantSkipped)
+	}
+}
+
+func TestParseAnsibleINIErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"unknown section", "[web:hostvars]\n", `line 1: unknown section type "hostvars"`},
+		{"vars without value", "[web:vars]\nansible_user\n", "line 2: expected key=value in [web:vars]"},
+		{"host var without value", "web ansible_user\n", `line 1: expected key=value, got "ansible_user"`},
+		{"unterminated quote", "web ansible_password='abc\n", "line 1: unterminated quote"},
+		{"bad range", "[web]\nweb[3:1]\n", "line 2: host range \"web[3:1]\": start after end"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseAnsibleINI([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+const yamlInventory = `all:
+  vars:
+    ansible_user: root
+  hosts:
+    bastion:
+      ansible_host: 10.0.0.1
+  children:
+    web:
+      vars:
+        ansible_user: deploy
+        ansible_port: 2222
+      hosts:
+        web[1:2]:
+        web-special:
+          ansible_user: special
+          ansible_port: 22
+    vaulted:
+      hosts:
+        secret1:
+          ansible_password: !vault |
+            $ANSIBLE_VAULT;1.1;AES256
+            62313365396662343061393464336163383764373764613633653634306231386433626436623361
+        templated:
+          ansible_host: "{{ lookup('env', 'HOST') }}"
+`
+
+func TestParseAnsibleYAML(t *testing.T) {
+	machines, skipped, err := ParseAnsibleYAML([]byte(yamlInventory))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.MachineCreate{
+		{Name: "bastion", Host: "10.0.0.1", Port: 22, User: "root"},
+		{Name: "web1", Host: "web1", Port: 2222, User: "deploy"},
+		{Name: "web2", Host: "web2", Port: 2222, User: "deploy"},
+		{Name: "web-special", Host: "web-special", Port: 22, User: "special"},
+	}
+	if !reflect.DeepEqual(machines, want) {
+		t.Errorf("machines:\n got %+v\nwant %+v", machines, want)
+	}
+	wantSkipped := []Skipped{
+		{Name: "secret1", Reason: "ansible_password is vault-encrypted"},
+		{Name: "templated", Reason: "ansible_host uses a Jinja template"},
+	}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Errorf("skipped:\n got %+v\nwant %+v", skipped, wantSkipped)
+	}
+}
+
+func TestParseAnsibleYAMLWithoutAll(t *testing.T) {
+	data := `web:
+  hosts:
+    web1:
+db:
+  vars:
+    ansible_user: postgres
+  hosts:
+    db1:
+      ansible_host: 10.0.0.5
+`
+	machines, _, err := ParseAnsibleYAML([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.MachineCreate{
+		{Name: "web1", Host: "web1", Port: 22},
+		{Name: "db1", Host: "10.0.0.5", Port: 22, User: "postgres"},
+	}
+	if !reflect.DeepEqual(machines, want) {
+		t.Errorf("machines:\n got %+v\nwant %+v", machines, want)
+	}
+}
+
+func TestParseAnsibleYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"not a mapping", "- web1\n", "inventory must be a mapping of groups"},
+		{"bad yaml", "all: [\n", "parse YAML"},
+		{"bad group", "all:\n  hosts: [web1]\n", "group all"},
+		{"bad range", "all:\n  hosts:\n    web[1:x]:\n", `host range "web[1:x]": bad bounds`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseAnsibleYAML([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpandRange(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"web", []string{"web"}},
+		{"web[01:03]", []string{"web01", "web02", "web03"}},
+		{"web[8:10]", []string{"web8", "web9", "web10"}},
+		{"web[1:5:2]", []string{"web1", "web3", "web5"}},
+		{"db-[a:c]", []string{"db-a", "db-b", "db-c"}},
+		{"DB[X:Z]", []string{"DBX", "DBY", "DBZ"}},
+		{"db-[a:e:2].lan", []string{"db-a.lan", "db-c.lan", "db-e.lan"}},
+		{"rack[1:2]-node[a:b]", []string{"rack1-nodea", "rack1-nodeb", "rack2-nodea", "rack2-nodeb"}},
+	}
+	for _, tt := range tests {
+		got, err := expandRange(tt.in)
+		if err != nil {
+			t.Errorf("expandRange(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandRange(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"web[1:", "web[1]", "web[1:2:3:4]", "web[3:1]", "web[1:2:0]", "web[a:zz]", "web[c:a]", "web[1:b]", "web[A:c]", "web[-:/]"} {
+		if got, err := expandRange(in); err == nil {
+			t.Errorf("expandRange(%q) = %q, want an error", in, got)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if _, _, err := Parse("terraform", nil); err == nil || !strings.Contains(err.Error(), "unknown inventory format") {
+		t.Errorf("err = %v, want an unknown format error", err)
+	}
+	machines, _, err := Parse(AnsibleINI, []byte("web1\n"))
+	if err != nil || len(machines) != 1 || machines[0].Name != "web1" {
+		t.Errorf("Parse(%s) = %+v, %v", AnsibleINI, machines, err)
+	}
+}
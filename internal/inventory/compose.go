@@ -0,0 +1,295 @@
+package inventory
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeOptions controls how a compose file is turned into machines.
+type ComposeOptions struct {
+	ServicePattern string // glob on service names; "" means all
+	SSHPort        int    // container port sshd listens on; 0 means 22
+	User           string // SSH user of the containers
+	// LookupEnv resolves ${VAR} in the file; nil uses the process environment.
+	LookupEnv func(string) (string, bool)
+}
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	ContainerName string `yaml:"container_name"`
+	Scale         *int   `yaml:"scale"`
+	Deploy        struct {
+		Replicas *int `yaml:"replicas"`
+	} `yaml:"deploy"`
+	Ports []yaml.Node `yaml:"ports"`
+}
+
+// hostPorts is the host side of one published port: a single port or a range.
+type hostPorts struct {
+	ip          string
+	first, last int
+}
+
+// ParseCompose reads a docker compose file and returns one machine per
+// published sshd port: services whose container SSH port is published get a
+// machine per replica, taking consecutive ports from a published range
+// (22221-22230:22). Machines are named after the service, with -1, -2, ...
+// for replicas, and use localhost unless the mapping binds a specific IP.
+func ParseCompose(data []byte, opts ComposeOptions) ([]types.MachineCreate, []Skipped, error) {
+	lookup := opts.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("parse compose file: %w", err)
+	}
+	if err := interpolateNode(&root, lookup); err != nil {
+		return nil, nil, err
+	}
+	var cf composeFile
+	if len(root.Content) > 0 {
+		if err := root.Decode(&cf); err != nil {
+			return nil, nil, fmt.Errorf("parse compose file: %w", err)
+		}
+	}
+	if len(cf.Services) == 0 {
+		return nil, nil, fmt.Errorf("compose file has no services")
+	}
+	sshPort := opts.SSHPort
+	if sshPort == 0 {
+		sshPort = 22
+	}
+
+	names := make([]string, 0, len(cf.Services))
+	for name := range cf.Services {
+		if opts.ServicePattern != "" {
+			ok, err := path.Match(opts.ServicePattern, name)
+			if err != nil {
+				return nil, nil, fmt.Errorf("--service-pattern: %w", err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		machines []types.MachineCreate
+		skipped  []Skipped
+	)
+	for _, name := range names {
+		svc := cf.Services[name]
+		published, reason := sshMapping(svc.Ports, sshPort)
+		if reason != "" {
+			skipped = append(skipped, Skipped{Name: name, Reason: reason})
+			continue
+		}
+
+		replicas := 1
+		switch {
+		case svc.Deploy.Replicas != nil:
+			replicas = *svc.Deploy.Replicas
+		case svc.Scale != nil:
+			replicas = *svc.Scale
+		}
+		if replicas <= 0 {
+			skipped = append(skipped, Skipped{Name: name, Reason: "scaled to 0 replicas"})
+			continue
+		}
+
+		host := "localhost"
+		if published.ip != "" && published.ip != "0.0.0.0" && published.ip != "::" {
+			host = published.ip
+		}
+		for i := 0; i < replicas; i++ {
+			m := types.MachineCreate{Name: name, Host: host, Port: published.first + i, User: opts.User}
+			if replicas > 1 {
+				m.Name = fmt.Sprintf("%s-%d", name, i+1)
+			} else if svc.ContainerName != "" {
+				m.Name = svc.ContainerName
+			}
+			if m.Port > published.last {
+				skipped = append(skipped, Skipped{
+					Name:   m.Name,
+					Reason: fmt.Sprintf("%d replicas but only %d published port(s)", replicas, published.last-published.first+1),
+				})
+				continue
+			}
+			machines = append(machines, m)
+		}
+	}
+	return machines, skipped, nil
+}
+
+// sshMapping finds the published host ports of the container SSH port, in
+// short ("127.0.0.1:22221-22230:22/tcp") or long ({target, published}) syntax.
+func sshMapping(ports []yaml.Node, sshPort int) (hostPorts, string) {
+	for _, n := range ports {
+		var (
+			ip, published, target, proto string
+		)
+		switch n.Kind {
+		case yaml.ScalarNode:
+			spec := n.Value
+			spec, proto, _ = strings.Cut(spec, "/")
+			parts := splitPortSpec(spec)
+			switch len(parts) {
+			case 1:
+				target = parts[0]
+			case 2:
+				published, target = parts[0], parts[1]
+			case 3:
+				ip, published, target = parts[0], parts[1], parts[2]
+			default:
+				return hostPorts{}, fmt.Sprintf("bad port mapping %q", n.Value)
+			}
+		case yaml.MappingNode:
+			var long struct {
+				Target    string `yaml:"target"`
+				Published string `yaml:"published"`
+				HostIP    string `yaml:"host_ip"`
+				Protocol  string `yaml:"protocol"`
+			}
+			if err := n.Decode(&long); err != nil {
+				return hostPorts{}, fmt.Sprintf("bad port mapping: %v", err)
+			}
+			ip, published, target, proto = long.HostIP, long.Published, long.Target, long.Protocol
+		default:
+			continue
+		}
+		if proto != "" && proto != "tcp" {
+			continue
+		}
+
+		tFirst, tLast, err := portRange(target)
+		if err != nil {
+			return hostPorts{}, err.Error()
+		}
+		if sshPort < tFirst || sshPort > tLast {
+			continue
+		}
+		if published == "" {
+			return hostPorts{}, fmt.Sprintf("port %d is published on a random host port", sshPort)
+		}
+		pFirst, pLast, err := portRange(published)
+		if err != nil {
+			return hostPorts{}, err.Error()
+		}
+		if tFirst != tLast {
+			// Paired ranges (2201-2210:22-31): take the matching host port.
+			p := pFirst + sshPort - tFirst
+			return hostPorts{ip: ip, first: p, last: p}, ""
+		}
+		return hostPorts{ip: ip, first: pFirst, last: pLast}, ""
+	}
+	return hostPorts{}, fmt.Sprintf("container port %d is not published", sshPort)
+}
+
+// splitPortSpec splits ip:published:target, keeping [ipv6] addresses whole.
+func splitPortSpec(spec string) []string {
+	if strings.HasPrefix(spec, "[") {
+		if i := strings.Index(spec, "]:"); i >= 0 {
+			return append([]string{spec[1:i]}, strings.Split(spec[i+2:], ":")...)
+		}
+	}
+	return strings.Split(spec, ":")
+}
+
+func portRange(s string) (int, int, error) {
+	a, b, isRange := strings.Cut(s, "-")
+	first, err := strconv.Atoi(a)
+	if err != nil || first <= 0 || first > 65535 {
+		return 0, 0, fmt.Errorf("bad port %q", s)
+	}
+	if !isRange {
+		return first, first, nil
+	}
+	last, err := strconv.Atoi(b)
+	if err != nil || last < first || last > 65535 {
+		return 0, 0, fmt.Errorf("bad port range %q", s)
+	}
+	return first, last, nil
+}
+
+// interpolateNode interpolates the scalar values of a parsed compose file,
+// as docker compose does: comments and mapping keys are left alone. Plain
+// scalars are re-typed after expansion, so replicas: ${N} is a number.
+func interpolateNode(n *yaml.Node, lookup func(string) (string, bool)) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		v, err := interpolate([]byte(n.Value), lookup)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		if string(v) != n.Value {
+			n.Value = string(v)
+			if n.Style == 0 {
+				n.Tag = ""
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			if err := interpolateNode(n.Content[i], lookup); err != nil {
+				return err
+			}
+		}
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			if err := interpolateNode(c, lookup); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var composeVar = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:?[-?])?([^}]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// interpolate expands $VAR, ${VAR}, ${VAR:-default}, ${VAR-default} and
+// ${VAR:?error} like docker compose does; $$ is a literal $.
+func interpolate(data []byte, lookup func(string) (string, bool)) ([]byte, error) {
+	var firstErr error
+	out := composeVar.ReplaceAllFunc(data, func(m []byte) []byte {
+		if string(m) == "$$" {
+			return []byte("$")
+		}
+		sub := composeVar.FindSubmatch(m)
+		name, op, arg := string(sub[1]), string(sub[2]), string(sub[3])
+		if name == "" {
+			name = string(sub[4])
+		}
+		v, ok := lookup(name)
+		switch op {
+		case ":-":
+			if v == "" {
+				v = arg
+			}
+		case "-":
+			if !ok {
+				v = arg
+			}
+		case ":?", "?":
+			if !ok || (op == ":?" && v == "") {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("compose variable %s: %s", name, arg)
+				}
+			}
+		}
+		return []byte(v)
+	})
+	return out, firstErr
+}
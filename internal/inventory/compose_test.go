@@ -0,0 +1,219 @@
+package inventory
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/types"
+)
+
+const composeYAML = `services:
+  alpine:
+    image: alpine-ssh
+    deploy:
+      replicas: 3
+    ports:
+      - "${BIND_IP:-127.0.0.1}:22221-22230:22"
+  debian:
+    container_name: deb
+    ports:
+      - "8080:80"
+      - target: 22
+        published: "${DEB_PORT:?set DEB_PORT}"
+        host_ip: 0.0.0.0
+  few:
+    scale: 3
+    ports:
+      - "2301-2302:22"
+  paired:
+    ports:
+      - "[::1]:2201-2210:20-29/tcp"
+  random:
+    ports:
+      - "22"
+  stopped:
+    deploy:
+      replicas: 0
+    ports:
+      - "2401:22"
+  udp:
+    ports:
+      - "2222:22/udp"
+  web:
+    ports:
+      - "8080:80"
+`
+
+// env returns a LookupEnv over vars.
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := vars[k]
+		return v, ok
+	}
+}
+
+func TestParseCompose(t *testing.T) {
+	opts := ComposeOptions{User: "root", LookupEnv: env(map[string]string{"DEB_PORT": "2222"})}
+	machines, skipped, err := ParseCompose([]byte(composeYAML), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.MachineCreate{
+		{Name: "alpine-1", Host: "127.0.0.1", Port: 22221, User: "root"},
+		{Name: "alpine-2", Host: "127.0.0.1", Port: 22222, User: "root"},
+		{Name: "alpine-3", Host: "127.0.0.1", Port: 22223, User: "root"},
+		{Name: "deb", Host: "localhost", Port: 2222, User: "root"},
+		{Name: "few-1", Host: "localhost", Port: 2301, User: "root"},
+		{Name: "few-2", Host: "localhost", Port: 2302, User: "root"},
+		{Name: "paired", Host: "::1", Port: 2203, User: "root"},
+	}
+	if !reflect.DeepEqual(machines, want) {
+		t.Errorf("machines:\n got %+v\nwant %+v", machines, want)
+	}
+	wantSkipped := []Skipped{
+		{Name: "few-3", Reason: "3 replicas but only 2 published port(s)"},
+		{Name: "random", Reason: "port 22 is published on a random host port"},
+		{Name: "stopped", Reason: "scaled to 0 replicas"},
+		{Name: "udp", Reason: "container port 22 is not published"},
+		{Name: "web", Reason: "container port 22 is not published"},
+	}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Errorf("skipped:\n got %+v\nwant %+v", skipped, wantSkipped)
+	}
+}
+
+func TestParseComposeOptions(t *testing.T) {
+	data := `services:
+  alpine:
+    ports:
+      - "${BIND_IP-127.0.0.1}:2200:2222"
+  debian:
+    ports:
+      - "2300:2222"
+`
+	opts := ComposeOptions{ServicePattern: "alp*", SSHPort: 2222, LookupEnv: env(map[string]string{"BIND_IP": ""})}
+	machines, skipped, err := ParseCompose([]byte(data), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ${VAR-default} keeps a set but empty variable: no IP means localhost.
+	want := []types.MachineCreate{{Name: "alpine", Host: "localhost", Port: 2200}}
+	if !reflect.DeepEqual(machines, want) || len(skipped) != 0 {
+		t.Errorf("got %+v, skipped %+v, want %+v", machines, skipped, want)
+	}
+}
+
+func TestParseComposeInterpolatesValuesOnly(t *testing.T) {
+	data := `# Needs ${SSH_PORT:?set SSH_PORT} in production; costs $5.
+services:
+  alpine: # ${ALSO:?not a value}
+    deploy:
+      replicas: ${REPLICAS}
+    labels:
+      ${KEY}: value
+    ports:
+      # - "${OLD_PORT:?unused}:22"
+      - "${SSH_PORT:-22221}-22230:22"
+`
+	opts := ComposeOptions{LookupEnv: env(map[string]string{"REPLICAS": "2"})}
+	machines, _, err := ParseCompose([]byte(data), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.MachineCreate{
+		{Name: "alpine-1", Host: "localhost", Port: 22221},
+		{Name: "alpine-2", Host: "localhost", Port: 22222},
+	}
+	if !reflect.DeepEqual(machines, want) {
+		t.Errorf("machines:\n got %+v\nwant %+v", machines, want)
+	}
+
+	_, _, err = ParseCompose([]byte(data), ComposeOptions{LookupEnv: env(map[string]string{"REPLICAS": "two"})})
+	if err == nil || !strings.Contains(err.Error(), "parse compose file") {
+		t.Errorf("non-numeric replicas: err = %v, want a parse error", err)
+	}
+	_, _, err = ParseCompose([]byte("services:\n  web:\n    image: ${IMAGE:?set IMAGE}\n"), ComposeOptions{LookupEnv: env(nil)})
+	if err == nil || err.Error() != "line 3: compose variable IMAGE: set IMAGE" {
+		t.Errorf("err = %v, want the line of the value", err)
+	}
+}
+
+func TestParseComposeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		opts    ComposeOptions
+		wantErr string
+	}{
+		{"required variable", composeYAML, ComposeOptions{}, "compose variable DEB_PORT: set DEB_PORT"},
+		{"no services", "version: '3'\n", ComposeOptions{}, "compose file has no services"},
+		{"bad yaml", "services: [\n", ComposeOptions{}, "parse compose file"},
+		{"bad pattern", "services:\n  web: {}\n", ComposeOptions{ServicePattern: "["}, "--service-pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.LookupEnv = env(nil)
+			_, _, err := ParseCompose([]byte(tt.data), tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestComposeBadPorts(t *testing.T) {
+	for _, spec := range []string{`"abc:22"`, `"2200-2100:22"`, `"1:2:3:4"`, `"70000:22"`} {
+		data := "services:\n  web:\n    ports:\n      - " + spec + "\n"
+		machines, skipped, err := ParseCompose([]byte(data), ComposeOptions{LookupEnv: env(nil)})
+		if err != nil {
+			t.Fatalf("%s: %v", spec, err)
+		}
+		if len(machines) != 0 || len(skipped) != 1 || skipped[0].Name != "web" {
+			t.Errorf("%s: got %+v, skipped %+v, want web skipped", spec, machines, skipped)
+		}
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	lookup := env(map[string]string{"SET": "value", "EMPTY": ""})
+	tests := []struct {
+		in, want string
+	}{
+		{"$SET", "value"},
+		{"${SET}", "value"},
+		{"a${SET}b", "avalueb"},
+		{"${UNSET}", ""},
+		{"${UNSET:-def}", "def"},
+		{"${EMPTY:-def}", "def"},
+		{"${UNSET-def}", "def"},
+		{"${EMPTY-def}", ""},
+		{"${SET:?must be set}", "value"},
+		{"$$SET", "$SET"},
+		{"$${SET}", "${SET}"},
+		{"cost: 5$", "cost: 5$"},
+	}
+	for _, tt := range tests {
+		got, err := interpolate([]byte(tt.in), lookup)
+		if err != nil {
+			t.Errorf("interpolate(%q): %v", tt.in, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("interpolate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	for in, wantErr := range map[string]string{
+		"${UNSET:?need UNSET}": "compose variable UNSET: need UNSET",
+		"${EMPTY:?need EMPTY}": "compose variable EMPTY: need EMPTY",
+		"${UNSET?}":            "compose variable UNSET",
+	} {
+		if _, err := interpolate([]byte(in), lookup); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("interpolate(%q) err = %v, want %q", in, err, wantErr)
+		}
+	}
+	if _, err := interpolate([]byte("${EMPTY?}"), lookup); err != nil {
+		t.Errorf("interpolate(${EMPTY?}): %v, want no error for a set variable", err)
+	}
+}
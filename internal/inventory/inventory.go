@@ -0,0 +1,184 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/types"
+)
+
+// Supported formats.
+const (
+	AnsibleINI  = "ansible-ini"
+	AnsibleYAML = "ansible-yaml"
+	SSHConfig   = "ssh-config"
+)
+
+// Formats lists the formats Parse accepts.
+var Formats = []string{AnsibleINI, AnsibleYAML, SSHConfig}
+
+// Skipped is an inventory entry that could not be turned into a machine.
+type Skipped struct {
+	Name   string
+	Reason string
+}
+
+// Parse reads an inventory in the given format. Machines keep the order of
+// the inventory; fields it does not set (typically the password) are left
+// empty for the caller to fill in.
+func Parse(format string, data []byte) ([]types.MachineCreate, []Skipped, error) {
+	switch format {
+	case AnsibleINI:
+		return ParseAnsibleINI(data)
+	case AnsibleYAML:
+		return ParseAnsibleYAML(data)
+	case SSHConfig:
+		return ParseSSHConfig(data)
+	}
+	return nil, nil, fmt.Errorf("unknown inventory format %q (want %s)", format, strings.Join(Formats, ", "))
+}
+
+// group is an Ansible group, shared by the INI and YAML parsers.
+type group struct {
+	hosts    []string
+	hostVars map[string]map[string]string
+	vars     map[string]string
+	children []string
+}
+
+func newGroup() *group {
+	return &group{hostVars: map[string]map[string]string{}, vars: map[string]string{}}
+}
+
+func (g *group) addHost(name string, vars map[string]string) {
+	if _, ok := g.hostVars[name]; !ok {
+		g.hosts = append(g.hosts, name)
+		g.hostVars[name] = map[string]string{}
+	}
+	for k, v := range vars {
+		g.hostVars[name][k] = v
+	}
+}
+
+// resolve walks the group tree from "all" and computes each host's variables.
+// As in Ansible, child group vars override parent group vars and host vars
+// override both.
+func resolve(groups map[string]*group) ([]types.MachineCreate, []Skipped) {
+	var order []string
+	groupVars := map[string]map[string]string{}
+	hostVars := map[string]map[string]string{}
+
+	var walk func(name string, inherited map[string]string, seen map[string]bool)
+	walk = func(name string, inherited map[string]string, seen map[string]bool) {
+		g, ok := groups[name]
+		if !ok || seen[name] {
+			return
+		}
+		seen[name] = true
+		defer delete(seen, name)
+
+		vars := merge(inherited, g.vars)
+		for _, h := range g.hosts {
+			if _, ok := groupVars[h]; !ok {
+				order = append(order, h)
+				groupVars[h] = map[string]string{}
+				hostVars[h] = map[string]string{}
+			}
+			for k, v := range vars {
+				groupVars[h][k] = v
+			}
+			for k, v := range g.hostVars[h] {
+				hostVars[h][k] = v
+			}
+		}
+		for _, c := range g.children {
+			walk(c, vars, seen)
+		}
+	}
+	walk("all", nil, map[string]bool{})
+
+	var (
+		machines []types.MachineCreate
+		skipped  []Skipped
+	)
+	for _, h := range order {
+		m, reason := fromAnsibleVars(h, merge(groupVars[h], hostVars[h]))
+		if reason != "" {
+			skipped = append(skipped, Skipped{Name: h, Reason: reason})
+			continue
+		}
+		machines = append(machines, m)
+	}
+	return machines, skipped
+}
+
+// fromAnsibleVars maps ansible_* connection variables onto a machine. It
+// returns a reason when the host cannot be used.
+func fromAnsibleVars(name string, vars map[string]string) (types.MachineCreate, string) {
+	get := func(keys ...string) string {
+		for _, k := range keys {
+			if v, ok := vars[k]; ok && v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	for _, k := range sortedKeys(vars) {
+		if !strings.HasPrefix(k, "ansible_") {
+			continue
+		}
+		if strings.Contains(vars[k], "{{") || strings.Contains(vars[k], "{%") {
+			return types.MachineCreate{}, k + " uses a Jinja template"
+		}
+		if strings.HasPrefix(strings.TrimSpace(vars[k]), "$ANSIBLE_VAULT") {
+			return types.MachineCreate{}, k + " is vault-encrypted"
+		}
+	}
+	if c := get("ansible_connection"); c != "" && c != "ssh" && c != "paramiko" && c != "smart" {
+		return types.MachineCreate{}, "ansible_connection=" + c + " is not SSH"
+	}
+
+	m := types.MachineCreate{
+		Name:     name,
+		Host:     get("ansible_host", "ansible_ssh_host"),
+		User:     get("ansible_user", "ansible_ssh_user"),
+		Password: get("ansible_password", "ansible_ssh_pass"),
+		Port:     22,
+
+		PrivateKeyFile: get("ansible_ssh_private_key_file", "ansible_private_key_file"),
+		Passphrase:     get("ansible_ssh_private_key_passphrase"),
+	}
+	if m.Host == "" {
+		m.Host = name
+	}
+	if p := get("ansible_port", "ansible_ssh_port"); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil || n <= 0 || n > 65535 {
+			return types.MachineCreate{}, fmt.Sprintf("invalid ansible_port %q", p)
+		}
+		m.Port = n
+	}
+	return m, ""
+}
+
+func merge(base, over map[string]string) map[string]string {
+	out := make(map[string]string, len(base)+len(over))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range over {
+		out[k] = v
+	}
+	return out
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
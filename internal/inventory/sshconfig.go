@@ -0,0 +1,202 @@
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/types"
+)
+
+// sshBlock is one Host block of an ssh_config file.
+type sshBlock struct {
+	patterns []string
+	opts     map[string]string // lower-case keyword -> first value
+}
+
+// matches follows ssh_config pattern rules: globs, and !negations that
+// exclude the host whatever else matches.
+func (b sshBlock) matches(alias string) bool {
+	ok := false
+	for _, p := range b.patterns {
+		neg := strings.HasPrefix(p, "!")
+		if m, _ := path.Match(strings.TrimPrefix(p, "!"), alias); m {
+			if neg {
+				return false
+			}
+			ok = true
+		}
+	}
+	return ok
+}
+
+// names reports whether the block lists alias itself rather than matching it
+// through a pattern.
+func (b sshBlock) names(alias string) bool {
+	for _, p := range b.patterns {
+		if p == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSSHConfig reads an OpenSSH client config. Every concrete Host alias
+// (no wildcards) becomes a machine; HostName, Port and User come from the
+// first block that sets them, as ssh itself resolves them, so "Host *"
+// defaults apply. IdentityFile is only taken from blocks naming the alias:
+// a default key is the user's own and must not be uploaded for every host.
+// Match blocks and Include are not evaluated and are reported as skipped.
+func ParseSSHConfig(data []byte) ([]types.MachineCreate, []Skipped, error) {
+	var (
+		blocks  []sshBlock
+		aliases []string
+		skipped []Skipped
+		cur     *sshBlock
+	)
+	// Options before the first Host apply to every host.
+	blocks = append(blocks, sshBlock{patterns: []string{"*"}, opts: map[string]string{}})
+	cur = &blocks[0]
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		key, value := splitSSHOption(line)
+		if value == "" {
+			return nil, nil, fmt.Errorf("line %d: %s without a value", lineNo, key)
+		}
+		switch strings.ToLower(key) {
+		case "host":
+			fields, err := splitFields(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			blocks = append(blocks, sshBlock{patterns: fields, opts: map[string]string{}})
+			cur = &blocks[len(blocks)-1]
+			for _, p := range fields {
+				if !strings.ContainsAny(p, "*?!") {
+					aliases = append(aliases, p)
+				}
+			}
+		case "match":
+			skipped = append(skipped, Skipped{Name: "Match " + value, Reason: "Match blocks are not supported"})
+			// Options up to the next Host belong to the Match block: drop them.
+			blocks = append(blocks, sshBlock{opts: map[string]string{}})
+			cur = &blocks[len(blocks)-1]
+		case "include":
+			skipped = append(skipped, Skipped{Name: "Include " + value, Reason: "Include is not supported; pass the included file separately"})
+		default:
+			k := strings.ToLower(key)
+			if _, ok := cur.opts[k]; !ok {
+				cur.opts[k] = unquote(value)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var machines []types.MachineCreate
+	seen := map[string]bool{}
+	for _, alias := range aliases {
+		if seen[alias] {
+			continue
+		}
+		seen[alias] = true
+		opts := map[string]string{}
+		for _, b := range blocks {
+			if !b.matches(alias) {
+				continue
+			}
+			for k, v := range b.opts {
+				if k == "identityfile" && !b.names(alias) {
+					continue
+				}
+				if _, ok := opts[k]; !ok {
+					opts[k] = v
+				}
+			}
+		}
+		m, reason := fromSSHOptions(alias, opts)
+		if reason != "" {
+			skipped = append(skipped, Skipped{Name: alias, Reason: reason})
+			continue
+		}
+		machines = append(machines, m)
+	}
+	return machines, skipped, nil
+}
+
+func fromSSHOptions(alias string, opts map[string]string) (types.MachineCreate, string) {
+	if pc := opts["proxycommand"]; pc != "" && !strings.EqualFold(pc, "none") {
+		return types.MachineCreate{}, "ProxyCommand is not supported"
+	}
+	if pj := opts["proxyjump"]; pj != "" && !strings.EqualFold(pj, "none") {
+		return types.MachineCreate{}, "ProxyJump is not supported"
+	}
+	m := types.MachineCreate{
+		Name:           alias,
+		Host:           alias,
+		Port:           22,
+		User:           opts["user"],
+		PrivateKeyFile: opts["identityfile"],
+	}
+	if h := opts["hostname"]; h != "" {
+		host, ok := expandHostName(h, alias)
+		if !ok {
+			return types.MachineCreate{}, fmt.Sprintf("HostName %q uses unsupported %% tokens", h)
+		}
+		m.Host = host
+	}
+	if p := opts["port"]; p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil || n <= 0 || n > 65535 {
+			return types.MachineCreate{}, fmt.Sprintf("invalid Port %q", p)
+		}
+		m.Port = n
+	}
+	return m, ""
+}
+
+// expandHostName expands the %h and %% tokens of a HostName; it reports
+// false for any other token.
+func expandHostName(h, alias string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(h); i++ {
+		if h[i] != '%' {
+			b.WriteByte(h[i])
+			continue
+		}
+		if i++; i == len(h) {
+			return "", false
+		}
+		switch h[i] {
+		case 'h':
+			b.WriteString(alias)
+		case '%':
+			b.WriteByte('%')
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// splitSSHOption splits "Keyword value" or "Keyword=value".
+func splitSSHOption(line string) (string, string) {
+	i := strings.IndexAny(line, " \t=")
+	if i < 0 {
+		return line, ""
+	}
+	key, rest := line[:i], strings.TrimLeft(line[i:], " \t")
+	rest = strings.TrimPrefix(rest, "=")
+	return key, strings.TrimSpace(rest)
+}
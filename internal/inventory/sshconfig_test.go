@@ -0,0 +1,194 @@
+package inventory
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/types"
+)
+
+func TestParseSSHConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		want        []types.MachineCreate
+		wantSkipped []Skipped
+	}{
+		{
+			name: "Host * defaults",
+			config: `Host web
+    HostName web.example.com
+
+Host *
+    User deploy
+    Port 2200
+`,
+			want: []types.MachineCreate{{Name: "web", Host: "web.example.com", Port: 2200, User: "deploy"}},
+		},
+		{
+			name: "first value wins",
+			config: `Host web
+    User first
+Host web
+    User second
+    Port 2222
+Host *
+    User third
+`,
+			want: []types.MachineCreate{{Name: "web", Host: "web", Port: 2222, User: "first"}},
+		},
+		{
+			name: "options before the first Host apply to all",
+			config: `User everyone
+Host web
+    User ignored
+`,
+			want: []types.MachineCreate{{Name: "web", Host: "web", Port: 22, User: "everyone"}},
+		},
+		{
+			name: "several aliases and wildcards",
+			config: `Host web1 web2 web-*
+    Port 2022
+Host db?
+    User pg
+`,
+			want: []types.MachineCreate{
+				{Name: "web1", Host: "web1", Port: 2022},
+				{Name: "web2", Host: "web2", Port: 2022},
+			},
+		},
+		{
+			name: "negation",
+			config: `Host *.lan !db.lan
+    User lanuser
+Host web.lan db.lan
+    Port 2200
+`,
+			want: []types.MachineCreate{
+				{Name: "web.lan", Host: "web.lan", Port: 2200, User: "lanuser"},
+				{Name: "db.lan", Host: "db.lan", Port: 2200},
+			},
+		},
+		{
+			name: "IdentityFile only from blocks naming the alias",
+			config: `Host web
+    IdentityFile ~/.ssh/web
+Host web-*
+    IdentityFile ~/.ssh/shared
+Host web-1
+    User u
+Host *
+    IdentityFile ~/.ssh/id_ed25519
+`,
+			want: []types.MachineCreate{
+				{Name: "web", Host: "web", Port: 22, PrivateKeyFile: "~/.ssh/web"},
+				{Name: "web-1", Host: "web-1", Port: 22, User: "u"},
+			},
+		},
+		{
+			name: "IdentityFile before the first Host is a default",
+			config: `IdentityFile ~/.ssh/id_rsa
+Host web
+`,
+			want: []types.MachineCreate{{Name: "web", Host: "web", Port: 22}},
+		},
+		{
+			name: "HostName tokens",
+			config: `Host web
+    HostName %h.example.com
+Host pct
+    HostName 100%%.example.com
+Host literal
+    HostName %%h.example.com
+Host remote
+    HostName %r.example.com
+Host trailing
+    HostName web%
+`,
+			want: []types.MachineCreate{
+				{Name: "web", Host: "web.example.com", Port: 22},
+				{Name: "pct", Host: "100%.example.com", Port: 22},
+				{Name: "literal", Host: "%h.example.com", Port: 22},
+			},
+			wantSkipped: []Skipped{
+				{Name: "remote", Reason: `HostName "%r.example.com" uses unsupported % tokens`},
+				{Name: "trailing", Reason: `HostName "web%" uses unsupported % tokens`},
+			},
+		},
+		{
+			name: "key=value syntax, case and quotes",
+			config: `host=web
+  hostname = "web.example.com"
+  PORT=2200
+`,
+			want: []types.MachineCreate{{Name: "web", Host: "web.example.com", Port: 2200}},
+		},
+		{
+			name: "proxies and bad ports",
+			config: `Host jump
+    ProxyJump bastion
+Host cmd
+    ProxyCommand ssh -W %h:%p bastion
+Host direct
+    ProxyCommand none
+Host badport
+    Port ssh
+`,
+			want: []types.MachineCreate{{Name: "direct", Host: "direct", Port: 22}},
+			wantSkipped: []Skipped{
+				{Name: "jump", Reason: "ProxyJump is not supported"},
+				{Name: "cmd", Reason: "ProxyCommand is not supported"},
+				{Name: "badport", Reason: `invalid Port "ssh"`},
+			},
+		},
+		{
+			name: "Match and Include",
+			config: `Include ~/.ssh/config.d/*
+Host web
+Match host web
+    User matched
+Host db
+`,
+			want: []types.MachineCreate{
+				{Name: "web", Host: "web", Port: 22},
+				{Name: "db", Host: "db", Port: 22},
+			},
+			wantSkipped: []Skipped{
+				{Name: "Include ~/.ssh/config.d/*", Reason: "Include is not supported; pass the included file separately"},
+				{Name: "Match host web", Reason: "Match blocks are not supported"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			machines, skipped, err := ParseSSHConfig([]byte(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(machines, tt.want) {
+				t.Errorf("machines:\n got %+v\nwant %+v", machines, tt.want)
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("skipped:\n got %+v\nwant %+v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestParseSSHConfigErrors(t *testing.T) {
+	tests := []struct {
+		config  string
+		wantErr string
+	}{
+		{"Host\n", "line 1: Host without a value"},
+		{"Host web\n    User\n", "line 2: User without a value"},
+		{"Host 'web\n", "line 1: unterminated quote"},
+	}
+	for _, tt := range tests {
+		_, _, err := ParseSSHConfig([]byte(tt.config))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ParseSSHConfig(%q) err = %v, want %q", tt.config, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package labels
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Requirement operators.
+const (
+	OpEquals    = "="
+	OpNotEquals = "!="
+	OpIn        = "in"
+	OpNotIn     = "notin"
+	OpExists    = "exists"
+	OpNotExists = "!"
+)
+
+// Requirement is one comma-separated term of a selector.
+type Requirement struct {
+	Key    string
+	Op     string
+	Values []string
+}
+
+// Selector is a conjunction of requirements, kubectl style:
+//
+//	os=alpine,gpu!=true          equality / inequality
+//	os in (alpine,debian)        set membership
+//	tier notin (big-ram)         set exclusion
+//	gpu, !cordoned               existence / absence
+type Selector []Requirement
+
+// Parse parses a label selector. An empty string selects everything.
+func Parse(s string) (Selector, error) {
+	var sel Selector
+	for _, term := range splitTerms(s) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		r, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, r)
+	}
+	return sel, nil
+}
+
+// splitTerms splits on commas outside parentheses.
+func splitTerms(s string) []string {
+	var out []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, s[start:])
+}
+
+func parseRequirement(term string) (Requirement, error) {
+	if strings.HasPrefix(term, "!") {
+		key := strings.TrimSpace(term[1:])
+		if err := validKey(key); err != nil {
+			return Requirement{}, err
+		}
+		return Requirement{Key: key, Op: OpNotExists}, nil
+	}
+	if i := strings.Index(term, " "); i > 0 {
+		key := term[:i]
+		rest := strings.TrimSpace(term[i:])
+		for _, op := range []string{OpNotIn, OpIn} {
+			if strings.HasPrefix(rest, op) {
+				vals, err := parseSet(strings.TrimSpace(rest[len(op):]))
+				if err != nil {
+					return Requirement{}, fmt.Errorf("selector %q: %w", term, err)
+				}
+				if err := validKey(key); err != nil {
+					return Requirement{}, err
+				}
+				return Requirement{Key: key, Op: op, Values: vals}, nil
+			}
+		}
+	}
+	for _, op := range []string{"!=", "==", "="} {
+		if k, v, ok := strings.Cut(term, op); ok {
+			k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+			if err := validKey(k); err != nil {
+				return Requirement{}, err
+			}
+			if op == "==" {
+				op = OpEquals
+			}
+			return Requirement{Key: k, Op: op, Values: []string{v}}, nil
+		}
+	}
+	if err := validKey(term); err != nil {
+		return Requirement{}, err
+	}
+	return Requirement{Key: term, Op: OpExists}, nil
+}
+
+func parseSet(s string) ([]string, error) {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return nil, fmt.Errorf("expected a set like (a,b)")
+	}
+	var vals []string
+	for _, v := range strings.Split(s[1:len(s)-1], ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			vals = append(vals, v)
+		}
+	}
+	return vals, nil
+}
+
+func validKey(k string) error {
+	if k == "" || strings.ContainsAny(k, " ,=!()") {
+		return fmt.Errorf("invalid label key %q", k)
+	}
+	return nil
+}
+
+// Matches reports whether a label set satisfies every requirement.
+func (s Selector) Matches(set map[string]string) bool {
+	for _, r := range s {
+		v, ok := set[r.Key]
+		switch r.Op {
+		case OpEquals:
+			if !ok || v != r.Values[0] {
+				return false
+			}
+		case OpNotEquals:
+			if ok && v == r.Values[0] {
+				return false
+			}
+		case OpIn:
+			if !ok || !contains(r.Values, v) {
+				return false
+			}
+		case OpNotIn:
+			if ok && contains(r.Values, v) {
+				return false
+			}
+		case OpExists:
+			if !ok {
+				return false
+			}
+		case OpNotExists:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// FromAny converts decoded JSON (e.g. an item's "labels" object) to a label set.
+func FromAny(v any) map[string]string {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, val := range m {
+		if val == nil {
+			continue
+		}
+		out[k] = fmt.Sprint(val)
+	}
+	return out
+}
+
+// ParseAssignments parses "key=value" pairs (and "key-" removals when
+// allowRemove is set). Removed keys are returned separately.
+func ParseAssignments(args []string, allowRemove bool) (set map[string]string, remove []string, err error) {
+	set = map[string]string{}
+	for _, a := range args {
+		if allowRemove && strings.HasSuffix(a, "-") && !strings.Contains(a, "=") {
+			key := strings.TrimSuffix(a, "-")
+			if err := validKey(key); err != nil {
+				return nil, nil, err
+			}
+			remove = append(remove, key)
+			continue
+		}
+		k, v, ok := strings.Cut(a, "=")
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid label %q (want key=value)", a)
+		}
+		if err := validKey(k); err != nil {
+			return nil, nil, err
+		}
+		set[k] = v
+	}
+	return set, remove, nil
+}
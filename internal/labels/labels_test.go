@@ -0,0 +1,164 @@
+package labels
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Selector
+	}{
+		{"", nil},
+		{" , ", nil},
+		{"os=alpine", Selector{{Key: "os", Op: OpEquals, Values: []string{"alpine"}}}},
+		{"os==alpine", Selector{{Key: "os", Op: OpEquals, Values: []string{"alpine"}}}},
+		{"os = alpine", Selector{{Key: "os", Op: OpEquals, Values: []string{"alpine"}}}},
+		{"gpu!=true", Selector{{Key: "gpu", Op: OpNotEquals, Values: []string{"true"}}}},
+		{"os=", Selector{{Key: "os", Op: OpEquals, Values: []string{""}}}},
+		{"os in (alpine, debian)", Selector{{Key: "os", Op: OpIn, Values: []string{"alpine", "debian"}}}},
+		{"tier notin (big-ram)", Selector{{Key: "tier", Op: OpNotIn, Values: []string{"big-ram"}}}},
+		{"os in ()", Selector{{Key: "os", Op: OpIn}}},
+		{"gpu", Selector{{Key: "gpu", Op: OpExists}}},
+		{"!cordoned", Selector{{Key: "cordoned", Op: OpNotExists}}},
+		{"! cordoned", Selector{{Key: "cordoned", Op: OpNotExists}}},
+		{
+			"os in (alpine,debian),gpu!=true, !cordoned ,zone",
+			Selector{
+				{Key: "os", Op: OpIn, Values: []string{"alpine", "debian"}},
+				{Key: "gpu", Op: OpNotEquals, Values: []string{"true"}},
+				{Key: "cordoned", Op: OpNotExists},
+				{Key: "zone", Op: OpExists},
+			},
+		},
+		{"example.com/team=infra", Selector{{Key: "example.com/team", Op: OpEquals, Values: []string{"infra"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr string
+	}{
+		{"=alpine", `invalid label key ""`},
+		{"!", `invalid label key ""`},
+		{"!=true", `invalid label key "=true"`},
+		{"os in alpine", "expected a set like (a,b)"},
+		{"os in (alpine", "expected a set like (a,b)"},
+		{"os notin alpine)", "expected a set like (a,b)"},
+		{"my os=alpine", `invalid label key "my os"`},
+		{"a b", `invalid label key "a b"`},
+		{"(os)", `invalid label key "(os)"`},
+		{"os=alpine,!", `invalid label key ""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			sel, err := Parse(tt.in)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %+v, want an error", tt.in, sel)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.in, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	alpine := map[string]string{"os": "alpine", "gpu": "true"}
+	debian := map[string]string{"os": "debian"}
+	cordoned := map[string]string{"os": "alpine", "cordoned": "true"}
+
+	tests := []struct {
+		selector string
+		set      map[string]string
+		want     bool
+	}{
+		{"", alpine, true},
+		{"", nil, true},
+		{"os=alpine", alpine, true},
+		{"os=alpine", debian, false},
+		{"os=alpine", nil, false},
+		{"gpu!=true", alpine, false},
+		{"gpu!=true", debian, true}, // a missing key is not equal to anything
+		{"os in (alpine,debian)", debian, true},
+		{"os in (alpine,debian)", map[string]string{"os": "arch"}, false},
+		{"os in (alpine)", map[string]string{}, false},
+		{"os notin (alpine)", debian, true},
+		{"os notin (alpine)", alpine, false},
+		{"os notin (alpine)", map[string]string{}, true},
+		{"gpu", alpine, true},
+		{"gpu", debian, false},
+		{"!cordoned", alpine, true},
+		{"!cordoned", cordoned, false},
+		{"os=alpine,!cordoned", alpine, true},
+		{"os=alpine,!cordoned", cordoned, false},
+		{"os=alpine,gpu", cordoned, false},
+		{"os=", map[string]string{"os": ""}, true},
+		{"os=", debian, false},
+	}
+	for _, tt := range tests {
+		sel, err := Parse(tt.selector)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.selector, err)
+		}
+		if got := sel.Matches(tt.set); got != tt.want {
+			t.Errorf("%q matches %v = %v, want %v", tt.selector, tt.set, got, tt.want)
+		}
+	}
+}
+
+func TestFromAny(t *testing.T) {
+	got := FromAny(map[string]any{"os": "alpine", "cores": float64(4), "gpu": true, "gone": nil})
+	want := map[string]string{"os": "alpine", "cores": "4", "gpu": "true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromAny = %v, want %v", got, want)
+	}
+	if got := FromAny([]any{"os"}); got != nil {
+		t.Errorf("FromAny(list) = %v, want nil", got)
+	}
+	if got := FromAny(nil); got != nil {
+		t.Errorf("FromAny(nil) = %v, want nil", got)
+	}
+}
+
+func TestParseAssignments(t *testing.T) {
+	set, remove, err := ParseAssignments([]string{"os=alpine", "note=a=b", "empty=", "gpu-"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"os": "alpine", "note": "a=b", "empty": ""}; !reflect.DeepEqual(set, want) {
+		t.Errorf("set = %v, want %v", set, want)
+	}
+	if want := []string{"gpu"}; !reflect.DeepEqual(remove, want) {
+		t.Errorf("remove = %v, want %v", remove, want)
+	}
+
+	for _, tt := range []struct {
+		args        []string
+		allowRemove bool
+	}{
+		{[]string{"gpu-"}, false},
+		{[]string{"os"}, true},
+		{[]string{"=alpine"}, true},
+		{[]string{"bad key=x"}, true},
+		{[]string{"-"}, true},
+	} {
+		if _, _, err := ParseAssignments(tt.args, tt.allowRemove); err == nil {
+			t.Errorf("ParseAssignments(%q, %v) succeeded, want an error", tt.args, tt.allowRemove)
+		}
+	}
+}
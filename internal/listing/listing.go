@@ -0,0 +1,224 @@
+package listing
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/labels"
+	"github.com/Jeomhps/projet-iac-cli/internal/output"
+)
+
+// Options are the client-side list operations shared by list commands.
+type Options struct {
+	Filters []string // "key=value", "key!=value", "port>22000", "name=alpine-*"
+	SortBy  string
+	Reverse bool
+	Fields  []string
+	Labels  string // label selector matched against each item's "labels"
+}
+
+// Active reports whether any list operation was requested.
+func (o Options) Active() bool {
+	return len(o.Filters) > 0 || o.SortBy != "" || o.Reverse || len(o.Fields) > 0 || o.Labels != ""
+}
+
+// Filter is one parsed --filter expression.
+type Filter struct {
+	Key   string
+	Op    string // = != < <= > >=
+	Value string
+}
+
+// filterOps are tried longest first so "<=" is not read as "<".
+var filterOps = []string{"!=", "<=", ">=", "==", "=", "<", ">"}
+
+// ParseFilter parses "key OP value". Keys may be dotted paths.
+func ParseFilter(s string) (Filter, error) {
+	best := -1
+	var op string
+	for _, o := range filterOps {
+		if i := strings.Index(s, o); i > 0 && (best < 0 || i < best || (i == best && len(o) > len(op))) {
+			best, op = i, o
+		}
+	}
+	if best < 0 {
+		return Filter{}, fmt.Errorf("invalid filter %q (want key=value, key!=value, key>N ...)", s)
+	}
+	f := Filter{
+		Key:   strings.TrimSpace(s[:best]),
+		Op:    op,
+		Value: strings.TrimSpace(s[best+len(op):]),
+	}
+	if f.Op == "==" {
+		f.Op = "="
+	}
+	if f.Key == "" {
+		return Filter{}, fmt.Errorf("invalid filter %q: missing key", s)
+	}
+	return f, nil
+}
+
+// Match reports whether item satisfies the filter. Equality supports glob
+// patterns (*, ?, [..]); a missing key only matches "!=".
+func (f Filter) Match(item any) bool {
+	v, ok := output.Lookup(item, f.Key)
+	if !ok || v == nil {
+		return f.Op == "!="
+	}
+	if vals, isList := v.([]any); isList && (f.Op == "=" || f.Op == "!=") {
+		// Lists (e.g. reservation machines) match when any element matches.
+		hit := false
+		for _, el := range vals {
+			if f.matchValue(el, "=") {
+				hit = true
+				break
+			}
+		}
+		return hit == (f.Op == "=")
+	}
+	return f.matchValue(v, f.Op)
+}
+
+func (f Filter) matchValue(v any, op string) bool {
+	if (op == "=" || op == "!=") && strings.ContainsAny(f.Value, "*?[") {
+		s := fmt.Sprint(v)
+		ok, err := path.Match(f.Value, s)
+		if err != nil {
+			return false
+		}
+		return ok == (op == "=")
+	}
+	var want any = f.Value
+	switch f.Value {
+	case "true", "false":
+		if _, isBool := v.(bool); isBool {
+			want = f.Value == "true"
+		}
+	}
+	return output.Compare(v, op, want)
+}
+
+// Apply filters, sorts and projects a JSON list body. Non-list bodies are
+// returned unchanged.
+func Apply(body []byte, o Options) ([]byte, error) {
+	if !o.Active() {
+		return body, nil
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body, nil
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return body, nil
+	}
+	items, err := Process(items, o)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(items)
+}
+
+// Process applies the options to decoded items.
+func Process(items []any, o Options) ([]any, error) {
+	filters := make([]Filter, 0, len(o.Filters))
+	for _, s := range o.Filters {
+		f, err := ParseFilter(s)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+
+	sel, err := labels.Parse(o.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]any, 0, len(items))
+	for _, it := range items {
+		if MatchAll(it, filters) && MatchLabels(it, sel) {
+			out = append(out, it)
+		}
+	}
+
+	if o.SortBy != "" {
+		key := o.SortBy
+		sort.SliceStable(out, func(i, j int) bool {
+			return less(out[i], out[j], key)
+		})
+	}
+	if o.Reverse {
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+	}
+
+	if len(o.Fields) > 0 {
+		for i, it := range out {
+			out[i] = project(it, o.Fields)
+		}
+	}
+	return out, nil
+}
+
+// MatchAll reports whether item satisfies every filter.
+func MatchAll(item any, filters []Filter) bool {
+	for _, f := range filters {
+		if !f.Match(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchLabels reports whether an item's "labels" satisfy sel.
+func MatchLabels(item any, sel labels.Selector) bool {
+	if len(sel) == 0 {
+		return true
+	}
+	m, _ := item.(map[string]any)
+	return sel.Matches(labels.FromAny(m["labels"]))
+}
+
+// less orders by key; numbers numerically, missing values last.
+func less(a, b any, key string) bool {
+	av, aok := output.Lookup(a, key)
+	bv, bok := output.Lookup(b, key)
+	aok, bok = aok && av != nil, bok && bv != nil
+	if !aok || !bok {
+		return aok && !bok
+	}
+	return output.Compare(av, "<", bv)
+}
+
+// project keeps only the given fields; dotted paths keep their nesting
+// ("labels.os" yields {"labels": {"os": ...}}).
+func project(item any, fields []string) any {
+	if _, ok := item.(map[string]any); !ok {
+		return item
+	}
+	m := map[string]any{}
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		v, ok := output.Lookup(item, f)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(f, ".")
+		dst := m
+		for _, p := range parts[:len(parts)-1] {
+			next, ok := dst[p].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				dst[p] = next
+			}
+			dst = next
+		}
+		dst[parts[len(parts)-1]] = v
+	}
+	return m
+}
@@ -0,0 +1,46 @@
+package output
+
+import "strconv"
+
+// Compare applies op (== != < <= > >=) to two decoded JSON values. Numbers
+// compare numerically (numeric strings included); everything else compares
+// by its string form.
+func Compare(a any, op string, b any) bool {
+	if af, ok := toFloat(a); ok {
+		if bf, ok := toFloat(b); ok {
+			return cmpOrdered(af, bf, op)
+		}
+	}
+	return cmpOrdered(jpString(a), jpString(b), op)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func cmpOrdered[T float64 | string](a, b T, op string) bool {
+	switch op {
+	case "==", "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
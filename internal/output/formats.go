@@ -0,0 +1,124 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func writeCompact(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+// WriteNDJSON writes each element of a list (or a single value) as one
+// compact JSON line.
+func WriteNDJSON(w io.Writer, v any) error {
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+	for _, it := range items {
+		if err := writeCompact(w, it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteYAML writes v as a YAML document.
+func WriteYAML(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(integralNumbers(v)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// integralNumbers converts whole float64 values to int64 so YAML shows 22221
+// rather than 22221.0 or 2.2221e+04.
+func integralNumbers(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			t[k] = integralNumbers(val)
+		}
+	case []any:
+		for i := range t {
+			t[i] = integralNumbers(t[i])
+		}
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) < 1<<53 {
+			return int64(t)
+		}
+	}
+	return v
+}
+
+// WriteDelimited writes v as CSV (sep ',') or TSV (sep '\t'). Wide-only columns
+// are included; missing values are empty fields.
+func WriteDelimited(w io.Writer, v any, cols []Column, sep rune, noHeaders bool) error {
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+	if len(items) > 0 && !allObjects(items) {
+		cols = []Column{Col("VALUE", "")}
+	} else {
+		cols = effectiveColumns(items, cols)
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = sep
+	if !noHeaders {
+		hdr := make([]string, len(cols))
+		for i, c := range cols {
+			hdr[i] = c.Header
+		}
+		if err := cw.Write(hdr); err != nil {
+			return err
+		}
+	}
+	for _, it := range items {
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			row[i] = field(lookupAny(it, c.Keys), sep)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// field renders a value for CSV/TSV: like a table cell, but empty for missing
+// values and with tabs/newlines flattened in TSV.
+func field(v any, sep rune) string {
+	var s string
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		s = t
+	case float64:
+		s = strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		s = cell(t)
+	}
+	if sep == '\t' {
+		s = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+	}
+	return s
+}
@@ -0,0 +1,461 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A small kubectl-style JSONPath template engine:
+//
+//	{.items[*].name}            fields, [n], [*], [a:b], ..name (recursive)
+//	{.items[?(@.port>22000)]}   filters with == != < <= > >= or bare existence
+//	{range .items[*]}...{end}   iteration, with @ bound to the current element
+//	{"\n"}                      quoted literals
+//
+// When the root is a list, ".items" refers to the list itself so that
+// kubectl habits keep working against this API's bare arrays.
+
+type jpNode struct {
+	text    string // literal text (expr == "" and !isRange)
+	expr    string
+	isRange bool
+	body    []jpNode
+}
+
+// parseJSONPathTemplate splits a template into literal text, {expr},
+// and {range expr}...{end} blocks.
+func parseJSONPathTemplate(s string) ([]jpNode, error) {
+	nodes, rest, err := parseJPNodes(s, false)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("jsonpath: unexpected {end}")
+	}
+	return nodes, nil
+}
+
+func parseJPNodes(s string, inRange bool) ([]jpNode, string, error) {
+	var nodes []jpNode
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '{')
+		if i < 0 {
+			nodes = append(nodes, jpNode{text: s})
+			s = ""
+			break
+		}
+		if i > 0 {
+			nodes = append(nodes, jpNode{text: s[:i]})
+		}
+		end := closingBrace(s[i:])
+		if end < 0 {
+			return nil, "", fmt.Errorf("jsonpath: unclosed '{'")
+		}
+		inner := strings.TrimSpace(s[i+1 : i+end])
+		s = s[i+end+1:]
+		switch {
+		case inner == "end":
+			if !inRange {
+				return nodes, "{end}", nil
+			}
+			return nodes, s, nil
+		case strings.HasPrefix(inner, "range "):
+			body, rest, err := parseJPNodes(s, true)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jpNode{isRange: true, expr: strings.TrimSpace(inner[len("range "):]), body: body})
+			s = rest
+		case strings.HasPrefix(inner, `"`) || strings.HasPrefix(inner, `'`):
+			lit, err := unquote(inner)
+			if err != nil {
+				return nil, "", fmt.Errorf("jsonpath: bad literal %s", inner)
+			}
+			nodes = append(nodes, jpNode{text: lit})
+		default:
+			nodes = append(nodes, jpNode{expr: inner})
+		}
+	}
+	if inRange {
+		return nil, "", fmt.Errorf("jsonpath: {range} without {end}")
+	}
+	return nodes, "", nil
+}
+
+// closingBrace returns the index of the '}' matching s[0] == '{', skipping
+// quoted strings.
+func closingBrace(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(s string) (string, error) {
+	if strings.HasPrefix(s, "'") {
+		s = `"` + strings.ReplaceAll(strings.Trim(s, "'"), `"`, `\"`) + `"`
+	}
+	return strconv.Unquote(s)
+}
+
+// writeJSONPath evaluates a JSONPath template against v.
+func writeJSONPath(w io.Writer, v any, tmpl string) error {
+	nodes, err := parseJSONPathTemplate(tmpl)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	if err := execJP(&sb, nodes, v, v); err != nil {
+		return err
+	}
+	out := sb.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err = io.WriteString(w, out)
+	return err
+}
+
+func execJP(sb *strings.Builder, nodes []jpNode, root, cur any) error {
+	for _, n := range nodes {
+		switch {
+		case n.isRange:
+			vals, err := evalJSONPath(n.expr, root, cur)
+			if err != nil {
+				return err
+			}
+			for _, el := range vals {
+				if err := execJP(sb, n.body, root, el); err != nil {
+					return err
+				}
+			}
+		case n.expr != "":
+			vals, err := evalJSONPath(n.expr, root, cur)
+			if err != nil {
+				return err
+			}
+			for i, el := range vals {
+				if i > 0 {
+					sb.WriteByte(' ')
+				}
+				sb.WriteString(jpString(el))
+			}
+		default:
+			sb.WriteString(n.text)
+		}
+	}
+	return nil
+}
+
+func jpString(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}
+
+// evalJSONPath evaluates one path expression. Paths start at the root ($ or a
+// leading '.') or at the current range element (@).
+func evalJSONPath(expr string, root, cur any) ([]any, error) {
+	expr = strings.TrimSpace(expr)
+	start := root
+	switch {
+	case strings.HasPrefix(expr, "$"):
+		expr = expr[1:]
+	case strings.HasPrefix(expr, "@"):
+		expr = expr[1:]
+		start = cur
+	default:
+		// A bare path inside {range} is relative to the element, as in kubectl.
+		start = cur
+	}
+	vals := []any{start}
+	for expr != "" {
+		var err error
+		vals, expr, err = jpStep(expr, vals, root)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return vals, nil
+}
+
+// jpStep applies the leading segment of expr to every value.
+func jpStep(expr string, vals []any, root any) ([]any, string, error) {
+	switch {
+	case strings.HasPrefix(expr, ".."):
+		name, rest := jpName(expr[2:])
+		var out []any
+		for _, v := range vals {
+			out = append(out, jpRecursive(v, name)...)
+		}
+		return out, rest, nil
+	case strings.HasPrefix(expr, "."):
+		name, rest := jpName(expr[1:])
+		if name == "" {
+			return vals, rest, nil
+		}
+		var out []any
+		for _, v := range vals {
+			out = append(out, jpField(v, name)...)
+		}
+		return out, rest, nil
+	case strings.HasPrefix(expr, "["):
+		end := closingBracket(expr)
+		if end < 0 {
+			return nil, "", fmt.Errorf("jsonpath: unclosed '[' in %q", expr)
+		}
+		inner, rest := strings.TrimSpace(expr[1:end]), expr[end+1:]
+		var out []any
+		for _, v := range vals {
+			r, err := jpSubscript(v, inner, root)
+			if err != nil {
+				return nil, "", err
+			}
+			out = append(out, r...)
+		}
+		return out, rest, nil
+	}
+	return nil, "", fmt.Errorf("jsonpath: unexpected %q", expr)
+}
+
+func jpName(s string) (string, string) {
+	i := strings.IndexAny(s, ".[")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+func closingBracket(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func jpField(v any, name string) []any {
+	switch t := v.(type) {
+	case map[string]any:
+		if name == "*" {
+			return mapValues(t)
+		}
+		if x, ok := t[name]; ok {
+			return []any{x}
+		}
+	case []any:
+		if name == "items" {
+			return []any{t}
+		}
+		if name == "*" {
+			return t
+		}
+	}
+	return nil
+}
+
+func mapValues(m map[string]any) []any {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]any, len(keys))
+	for i, k := range keys {
+		out[i] = m[k]
+	}
+	return out
+}
+
+func jpRecursive(v any, name string) []any {
+	var out []any
+	switch t := v.(type) {
+	case map[string]any:
+		if x, ok := t[name]; ok {
+			out = append(out, x)
+		} else if name == "*" {
+			out = append(out, mapValues(t)...)
+		}
+		for _, child := range mapValues(t) {
+			out = append(out, jpRecursive(child, name)...)
+		}
+	case []any:
+		if name == "*" {
+			out = append(out, t...)
+		}
+		for _, child := range t {
+			out = append(out, jpRecursive(child, name)...)
+		}
+	}
+	return out
+}
+
+func jpSubscript(v any, inner string, root any) ([]any, error) {
+	switch {
+	case inner == "*":
+		return jpField(v, "*"), nil
+	case strings.HasPrefix(inner, "?(") && strings.HasSuffix(inner, ")"):
+		list, ok := v.([]any)
+		if !ok {
+			return nil, nil
+		}
+		var out []any
+		for _, el := range list {
+			ok, err := jpFilter(strings.TrimSpace(inner[2:len(inner)-1]), root, el)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				out = append(out, el)
+			}
+		}
+		return out, nil
+	case strings.HasPrefix(inner, "'") || strings.HasPrefix(inner, `"`):
+		name, err := unquote(inner)
+		if err != nil {
+			return nil, fmt.Errorf("jsonpath: bad key %s", inner)
+		}
+		return jpField(v, name), nil
+	}
+
+	list, ok := v.([]any)
+	if !ok {
+		return nil, nil
+	}
+	if a, b, isSlice := strings.Cut(inner, ":"); isSlice {
+		lo, hi := 0, len(list)
+		var err error
+		if s := strings.TrimSpace(a); s != "" {
+			if lo, err = strconv.Atoi(s); err != nil {
+				return nil, fmt.Errorf("jsonpath: bad slice [%s]", inner)
+			}
+		}
+		if s := strings.TrimSpace(b); s != "" {
+			if hi, err = strconv.Atoi(s); err != nil {
+				return nil, fmt.Errorf("jsonpath: bad slice [%s]", inner)
+			}
+		}
+		lo, hi = clampIndex(lo, len(list)), clampIndex(hi, len(list))
+		if lo >= hi {
+			return nil, nil
+		}
+		return list[lo:hi], nil
+	}
+	i, err := strconv.Atoi(inner)
+	if err != nil {
+		return nil, fmt.Errorf("jsonpath: bad subscript [%s]", inner)
+	}
+	if i < 0 {
+		i += len(list)
+	}
+	if i < 0 || i >= len(list) {
+		return nil, nil
+	}
+	return []any{list[i]}, nil
+}
+
+func clampIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+var jpOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// jpFilter evaluates "@.a.b OP literal" (or a bare path for existence).
+func jpFilter(cond string, root, el any) (bool, error) {
+	for _, op := range jpOps {
+		lhs, rhs, found := strings.Cut(cond, op)
+		if !found {
+			continue
+		}
+		lv, err := evalJSONPath(strings.TrimSpace(lhs), root, el)
+		if err != nil {
+			return false, err
+		}
+		rv, err := jpOperand(strings.TrimSpace(rhs), root, el)
+		if err != nil {
+			return false, err
+		}
+		if len(lv) == 0 {
+			return false, nil
+		}
+		return Compare(lv[0], op, rv), nil
+	}
+	vals, err := evalJSONPath(cond, root, el)
+	if err != nil {
+		return false, err
+	}
+	return len(vals) > 0 && vals[0] != nil && vals[0] != false, nil
+}
+
+func jpOperand(s string, root, el any) (any, error) {
+	switch {
+	case strings.HasPrefix(s, "@") || strings.HasPrefix(s, "$"):
+		vals, err := evalJSONPath(s, root, el)
+		if err != nil || len(vals) == 0 {
+			return nil, err
+		}
+		return vals[0], nil
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		return unquote(s)
+	case s == "true" || s == "false":
+		return s == "true", nil
+	case s == "null":
+		return nil, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return s, nil
+}
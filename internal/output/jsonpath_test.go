@@ -0,0 +1,149 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// storeJSON is kubectl's jsonpath test fixture, trimmed.
+const storeJSON = `{
+  "Store": {
+    "Book": [
+      {"Category": "reference", "Author": "Nigel Rees", "Title": "Sayings of the Centurey", "Price": 8.95},
+      {"Category": "fiction", "Author": "Evelyn Waugh", "Title": "Sword of Honour", "Price": 12.99},
+      {"Category": "fiction", "Author": "Herman Melville", "Title": "Moby Dick", "Isbn": "0-553-21311-3", "Price": 8.99}
+    ],
+    "Bicycle": [
+      {"Color": "red", "Price": 19.95, "IsNew": true},
+      {"Color": "green", "Price": 20.01, "IsNew": false}
+    ],
+    "Labels": {"engieer": 10, "web/html": 15, "k8s-app": 20}
+  }
+}`
+
+// machinesJSON is a bare list, as this API returns it.
+const machinesJSON = `[
+  {"name": "alpine-1", "port": 22221, "reserved": true, "reserved_by": "alice", "labels": {"os": "alpine"}},
+  {"name": "alpine-2", "port": 22222, "reserved": false, "labels": {"os": "alpine", "cordoned": "true"}},
+  {"name": "deb-1", "port": 22301, "reserved": false, "labels": {"os": "debian"}}
+]`
+
+func decodeJSON(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestJSONPath(t *testing.T) {
+	store := decodeJSON(t, storeJSON)
+	machines := decodeJSON(t, machinesJSON)
+
+	tests := []struct {
+		name     string
+		template string
+		input    any
+		want     string
+	}{
+		{"plain text", "hello jsonpath", store, "hello jsonpath"},
+		{"field", "{.Store.Bicycle[0].Color}", store, "red"},
+		{"dollar root", "{$.Store.Bicycle[1].Color}", store, "green"},
+		{"number", "{.Store.Book[0].Price}", store, "8.95"},
+		{"bool", "{.Store.Bicycle[0].IsNew}", store, "true"},
+		{"object as json", "{.Store.Labels}", store, `{"engieer":10,"k8s-app":20,"web/html":15}`},
+		{"quoted key", "{.Store.Labels['web/html']}", store, "15"},
+		{"double-quoted key", `{.Store.Labels["k8s-app"]}`, store, "20"},
+		{"all elements", "{.Store.Book[*].Author}", store, "Nigel Rees Evelyn Waugh Herman Melville"},
+		{"wildcard field", "{.Store.Labels.*}", store, "10 20 15"},
+		{"negative index", "{.Store.Book[-1].Title}", store, "Moby Dick"},
+		{"slice", "{.Store.Book[0:2].Title}", store, "Sayings of the Centurey Sword of Honour"},
+		{"open slice", "{.Store.Book[1:].Category}", store, "fiction fiction"},
+		{"negative slice", "{.Store.Book[-2:].Author}", store, "Evelyn Waugh Herman Melville"},
+		{"index out of range", "{.Store.Book[7].Title}", store, ""},
+		{"missing field", "{.Store.Car}", store, ""},
+		{"recursive", "{..Color}", store, "red green"},
+		{"recursive price", "{.Store.Bicycle..Price}", store, "19.95 20.01"},
+		{"filter number", "{.Store.Book[?(@.Price<10)].Title}", store, "Sayings of the Centurey Moby Dick"},
+		{"filter string", "{.Store.Book[?(@.Category=='reference')].Author}", store, "Nigel Rees"},
+		{"filter not equal", `{.Store.Book[?(@.Category!="fiction")].Author}`, store, "Nigel Rees"},
+		{"filter existence", "{.Store.Book[?(@.Isbn)].Title}", store, "Moby Dick"},
+		{"filter bool", "{.Store.Bicycle[?(@.IsNew==true)].Color}", store, "red"},
+		{"filter against root", "{.Store.Book[?(@.Price>$.Store.Book[0].Price)].Title}", store, "Sword of Honour Moby Dick"},
+		{"quoted literals", `{"a"}{'b'}{"\t"}c`, store, "ab\tc"},
+		{"braces in literal", `{"{x}"}`, store, "{x}"},
+		{
+			"range",
+			`{range .Store.Book[*]}{.Author}{"\t"}{.Price}{"\n"}{end}`, store,
+			"Nigel Rees\t8.95\nEvelyn Waugh\t12.99\nHerman Melville\t8.99\n",
+		},
+		{
+			"range with @ and root",
+			`{range .Store.Bicycle[*]}{@.Color}/{$.Store.Book[0].Category} {end}`, store,
+			"red/reference green/reference ",
+		},
+		{
+			// Object wildcards visit keys in sorted order.
+			"nested range",
+			`{range .Store.Bicycle[*]}[{range .*}{@} {end}]{end}`, store,
+			"[red true 19.95 ][green false 20.01 ]",
+		},
+		{"items on a bare list", "{.items[*].name}", machines, "alpine-1 alpine-2 deb-1"},
+		{"bare list index", "{[1].name}", machines, "alpine-2"},
+		{"filter on a bare list", "{.items[?(@.port>22221)].name}", machines, "alpine-2 deb-1"},
+		{"filter on nested field", "{.items[?(@.labels.os=='debian')].name}", machines, "deb-1"},
+		{"filter on missing field", "{.items[?(@.reserved_by=='alice')].name}", machines, "alpine-1"},
+		{
+			"range over a bare list",
+			`{range .items[*]}{.name}={.labels.os}{"\n"}{end}`, machines,
+			"alpine-1=alpine\nalpine-2=alpine\ndeb-1=debian\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := writeJSONPath(&sb, tt.input, tt.template); err != nil {
+				t.Fatalf("writeJSONPath(%q): %v", tt.template, err)
+			}
+			want := tt.want
+			if !strings.HasSuffix(want, "\n") {
+				want += "\n"
+			}
+			if got := sb.String(); got != want {
+				t.Errorf("writeJSONPath(%q) = %q, want %q", tt.template, got, want)
+			}
+		})
+	}
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	store := decodeJSON(t, storeJSON)
+	tests := []struct {
+		name     string
+		template string
+		wantErr  string
+	}{
+		{"unclosed brace", "{.Store", "unclosed '{'"},
+		{"range without end", "{range .Store.Book[*]}{.Title}", "{range} without {end}"},
+		{"end without range", "{.Store}{end}", "unexpected {end}"},
+		{"unclosed bracket", "{.Store.Book[0}", "unclosed '['"},
+		{"bad subscript", "{.Store.Book[x]}", "bad subscript [x]"},
+		{"bad slice", "{.Store.Book[a:1]}", "bad slice [a:1]"},
+		{"bad literal", `{"abc}`, "unclosed '{'"},
+		{"unexpected token", "{.Store.Book[0]Title}", "unexpected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := writeJSONPath(&sb, store, tt.template)
+			if err == nil {
+				t.Fatalf("writeJSONPath(%q) = %q, want an error", tt.template, sb.String())
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("writeJSONPath(%q) error = %q, want it to contain %q", tt.template, err, tt.wantErr)
+			}
+		})
+	}
+}
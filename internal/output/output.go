@@ -1,11 +1,10 @@
 package output
 
 import (
-	"encoding/json"
+	"bytes"
 	"os"
 	"strings"
 
-	"github.com/TylerBrock/colorjson"
 	"golang.org/x/term"
 )
 
@@ -25,30 +24,19 @@ func shouldColor(mode string) bool {
 	}
 }
 
+// UseColor reports whether output should be coloured for the given mode
+// (auto|always|never), honouring NO_COLOR.
+func UseColor(mode string) bool {
+	return shouldColor(mode)
+}
+
 // FormatJSON pretty-prints and optionally colorizes JSON.
 // If body is not valid JSON, it returns the input as-is.
 func FormatJSON(body []byte, colorMode string) string {
-	// Try to parse JSON once
-	var obj any
-	if err := json.Unmarshal(body, &obj); err != nil {
-		// Not JSON: print raw
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, bytes.NewReader(body), shouldColor(colorMode)); err != nil {
+		// Not (complete) JSON: print raw
 		return string(body)
 	}
-
-	// Pretty JSON without colors
-	pretty, _ := json.MarshalIndent(obj, "", "  ")
-
-	if !shouldColor(colorMode) {
-		return string(pretty)
-	}
-
-	// Colorized JSON
-	f := colorjson.NewFormatter()
-	f.Indent = 2
-	colored, err := f.Marshal(obj)
-	if err != nil {
-		// Fallback to non-colored pretty
-		return string(pretty)
-	}
-	return string(colored)
+	return strings.TrimRight(buf.String(), "\n")
 }
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+)
+
+// ApplyQuery runs a jq expression over a JSON body in-process and returns the
+// result as JSON. Results are always collected into an array, however many
+// there are, so scripts get the same shape whatever the data; with first,
+// only the first result is returned as-is (null when there is none).
+func ApplyQuery(body []byte, expr string, first bool) ([]byte, error) {
+	q, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parse query: %w", err)
+	}
+	code, err := gojq.Compile(q)
+	if err != nil {
+		return nil, fmt.Errorf("compile query: %w", err)
+	}
+	var in any
+	if err := json.Unmarshal(body, &in); err != nil {
+		return nil, fmt.Errorf("query: response is not JSON: %w", err)
+	}
+
+	results := []any{}
+	iter := code.Run(in)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			if herr, ok := err.(*gojq.HaltError); ok && herr.Value() == nil {
+				break
+			}
+			return nil, fmt.Errorf("query: %w", err)
+		}
+		results = append(results, v)
+		if first {
+			return json.Marshal(v)
+		}
+	}
+	if first {
+		return []byte("null"), nil
+	}
+	return json.Marshal(results)
+}
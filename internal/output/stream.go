@@ -0,0 +1,181 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ANSI colours matching the previous colorjson defaults.
+const (
+	colorKey    = "\033[37m"
+	colorString = "\033[32m"
+	colorBool   = "\033[33m"
+	colorNumber = "\033[36m"
+	colorNull   = "\033[35m"
+	colorReset  = "\033[0m"
+)
+
+// WriteJSON streams JSON from r to w, pretty-printed with a two-space indent
+// and optionally coloured. It works token by token, so output starts at once
+// and memory stays bounded regardless of the document size; key order is
+// preserved. Input that does not start like JSON is copied through unchanged.
+func WriteJSON(w io.Writer, r io.Reader, color bool) error {
+	br := bufio.NewReader(r)
+	if !startsLikeJSON(br) {
+		_, err := io.Copy(w, br)
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	p := &streamPrinter{w: bw, color: color}
+	dec := json.NewDecoder(br)
+	dec.UseNumber()
+	for {
+		err := p.value(dec)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			bw.Flush()
+			return err
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// startsLikeJSON peeks at the first non-space byte.
+func startsLikeJSON(br *bufio.Reader) bool {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return false
+		}
+		switch c := b[0]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			br.ReadByte()
+		case c == '{' || c == '[' || c == '"' || c == '-' || (c >= '0' && c <= '9'):
+			return true
+		case c == 't' || c == 'f' || c == 'n':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+type streamPrinter struct {
+	w     *bufio.Writer
+	color bool
+	depth int
+}
+
+func (p *streamPrinter) paint(c, s string) {
+	if p.color {
+		p.w.WriteString(c)
+		p.w.WriteString(s)
+		p.w.WriteString(colorReset)
+		return
+	}
+	p.w.WriteString(s)
+}
+
+func (p *streamPrinter) newline() {
+	p.w.WriteByte('\n')
+	p.w.WriteString(strings.Repeat("  ", p.depth))
+}
+
+// value prints the next complete JSON value from dec.
+func (p *streamPrinter) value(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			return p.container(dec, '{', '}')
+		case '[':
+			return p.container(dec, '[', ']')
+		}
+		return fmt.Errorf("unexpected %q in JSON", t)
+	case string:
+		p.paint(colorString, quote(t))
+	case json.Number:
+		p.paint(colorNumber, t.String())
+	case bool:
+		if t {
+			p.paint(colorBool, "true")
+		} else {
+			p.paint(colorBool, "false")
+		}
+	case nil:
+		p.paint(colorNull, "null")
+	}
+	return nil
+}
+
+// container prints an object or array whose opening delimiter was consumed.
+func (p *streamPrinter) container(dec *json.Decoder, open, close byte) error {
+	p.w.WriteByte(open)
+	if !dec.More() {
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return unexpectedEOF(err)
+		}
+		p.w.WriteByte(close)
+		return nil
+	}
+	p.depth++
+	first := true
+	for dec.More() {
+		if !first {
+			p.w.WriteByte(',')
+		}
+		first = false
+		p.newline()
+		if open == '{' {
+			tok, err := dec.Token()
+			if err != nil {
+				return unexpectedEOF(err)
+			}
+			key, _ := tok.(string)
+			p.paint(colorKey, quote(key)+":")
+			p.w.WriteByte(' ')
+		}
+		if err := p.value(dec); err != nil {
+			return unexpectedEOF(err)
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return unexpectedEOF(err)
+	}
+	p.depth--
+	p.newline()
+	p.w.WriteByte(close)
+	// Flush per top-level element so large lists appear progressively.
+	if p.depth <= 1 {
+		return p.w.Flush()
+	}
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// quote encodes s as a JSON string without HTML escaping.
+func quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimRight(buf.String(), "\n")
+}
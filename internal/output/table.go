@@ -0,0 +1,354 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+)
+
+// Output formats selectable with -o.
+const (
+	Table  = "table"
+	Wide   = "wide"
+	Pretty = "pretty" // indented, optionally coloured JSON
+	JSON   = "json"   // compact JSON
+	NDJSON = "ndjson" // one compact JSON value per line
+	YAML   = "yaml"
+	CSV    = "csv"
+	TSV    = "tsv"
+)
+
+// Column describes one table column. Keys are tried in order and the first
+// present in an item supplies the value; a key may be a dotted path
+// ("reservation.username"), and paths crossing arrays collect every element.
+type Column struct {
+	Header string
+	Keys   []string
+	Wide   bool // only shown with -o wide
+}
+
+// Col is shorthand for a Column shown in both table and wide output.
+func Col(header string, keys ...string) Column {
+	return Column{Header: header, Keys: keys}
+}
+
+// WideCol is shorthand for a Column only shown with -o wide.
+func WideCol(header string, keys ...string) Column {
+	return Column{Header: header, Keys: keys, Wide: true}
+}
+
+// Options controls how Print renders a response.
+type Options struct {
+	Format     string // see the format constants ("" picks table on a TTY, pretty otherwise)
+	Color      string // auto|always|never
+	NoHeaders  bool
+	Columns    []Column // nil derives columns from the data
+	Select     []string // explicit column keys for table/wide/csv/tsv (overrides Columns)
+	Query      string   // jq expression applied before formatting
+	QueryFirst bool     // keep only the first query result, unwrapped
+}
+
+// ResolveFormat returns the effective format: explicit value, or table when
+// stdout is a terminal and pretty JSON otherwise.
+func ResolveFormat(format string) string {
+	format = strings.TrimSpace(format)
+	if format != "" {
+		name, arg := splitFormat(format)
+		if isTemplateFormat(name) {
+			return name + "=" + arg
+		}
+		return name
+	}
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		return Table
+	}
+	return Pretty
+}
+
+// ValidFormat reports whether format is supported.
+func ValidFormat(format string) bool {
+	name, _ := splitFormat(format)
+	switch name {
+	case "", Table, Wide, Pretty, JSON, NDJSON, YAML, CSV, TSV:
+		return true
+	}
+	return isTemplateFormat(name)
+}
+
+// Print writes body to w in the selected format. Bodies that are not JSON are
+// written as-is.
+func Print(w io.Writer, body []byte, opts Options) error {
+	if opts.Query != "" {
+		b, err := ApplyQuery(body, opts.Query, opts.QueryFirst)
+		if err != nil {
+			return err
+		}
+		body = b
+	}
+	format := ResolveFormat(opts.Format)
+	if format == Pretty {
+		_, err := fmt.Fprintln(w, FormatJSON(body, opts.Color))
+		return err
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		_, err := fmt.Fprintln(w, string(body))
+		return err
+	}
+	if name, arg := splitFormat(format); isTemplateFormat(name) {
+		return writeTemplate(w, v, name, arg)
+	}
+	cols := opts.Columns
+	if len(opts.Select) > 0 {
+		cols = SelectColumns(opts.Select)
+	}
+	switch format {
+	case Table, Wide:
+		return WriteTable(w, v, cols, format == Wide, opts.NoHeaders)
+	case JSON:
+		return writeCompact(w, v)
+	case NDJSON:
+		return WriteNDJSON(w, v)
+	case YAML:
+		return WriteYAML(w, v)
+	case CSV:
+		return WriteDelimited(w, v, cols, ',', opts.NoHeaders)
+	case TSV:
+		return WriteDelimited(w, v, cols, '\t', opts.NoHeaders)
+	default:
+		return fmt.Errorf("unknown output format %q", opts.Format)
+	}
+}
+
+// SelectColumns builds columns from user-given keys (dotted paths allowed).
+func SelectColumns(keys []string) []Column {
+	cols := make([]Column, 0, len(keys))
+	for _, k := range keys {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+		cols = append(cols, Col(strings.ToUpper(k), k))
+	}
+	return cols
+}
+
+// WriteTable renders v (a list of objects, a single object or scalars) as an
+// aligned table.
+func WriteTable(w io.Writer, v any, cols []Column, wide, noHeaders bool) error {
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+	if len(items) > 0 && !allObjects(items) {
+		// Scalars (or mixed): one value per line
+		for _, it := range items {
+			fmt.Fprintln(w, cell(it))
+		}
+		return nil
+	}
+	cols = effectiveColumns(items, cols)
+	var shown []Column
+	for _, c := range cols {
+		if c.Wide && !wide {
+			continue
+		}
+		shown = append(shown, c)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if !noHeaders {
+		hdr := make([]string, len(shown))
+		for i, c := range shown {
+			hdr[i] = c.Header
+		}
+		fmt.Fprintln(tw, strings.Join(hdr, "\t"))
+	}
+	for _, it := range items {
+		row := make([]string, len(shown))
+		for i, c := range shown {
+			row[i] = cell(lookupAny(it, c.Keys))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// TableLines renders items like WriteTable and returns the lines (header
+// first unless noHeaders). marks, when non-nil, adds a leading marker column
+// (one entry per item) so callers can flag rows, e.g. in watch mode.
+func TableLines(items []any, cols []Column, wide, noHeaders bool, marks []string) []string {
+	cols = effectiveColumns(items, cols)
+	var shown []Column
+	for _, c := range cols {
+		if c.Wide && !wide {
+			continue
+		}
+		shown = append(shown, c)
+	}
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+	if !noHeaders {
+		hdr := make([]string, 0, len(shown)+1)
+		if marks != nil {
+			hdr = append(hdr, " ")
+		}
+		for _, c := range shown {
+			hdr = append(hdr, c.Header)
+		}
+		fmt.Fprintln(tw, strings.Join(hdr, "\t"))
+	}
+	for i, it := range items {
+		row := make([]string, 0, len(shown)+1)
+		if marks != nil {
+			row = append(row, marks[i])
+		}
+		for _, c := range shown {
+			row = append(row, cell(lookupAny(it, c.Keys)))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	tw.Flush()
+	return strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+}
+
+func allObjects(items []any) bool {
+	for _, it := range items {
+		if _, ok := it.(map[string]any); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// effectiveColumns returns cols, or columns derived from the data when cols is
+// nil or matches nothing (e.g. after --query reshaped the items).
+func effectiveColumns(items []any, cols []Column) []Column {
+	for _, it := range items {
+		for _, c := range cols {
+			if lookupAny(it, c.Keys) != nil {
+				return cols
+			}
+		}
+	}
+	if len(items) == 0 && cols != nil {
+		return cols
+	}
+	return deriveColumns(items)
+}
+
+// deriveColumns builds one column per top-level key, in sorted order.
+func deriveColumns(items []any) []Column {
+	seen := map[string]bool{}
+	var keys []string
+	for _, it := range items {
+		for k := range it.(map[string]any) {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	cols := make([]Column, len(keys))
+	for i, k := range keys {
+		cols[i] = Col(strings.ToUpper(strings.ReplaceAll(k, "_", " ")), k)
+	}
+	return cols
+}
+
+// lookupAny returns the value of the first key present in item.
+func lookupAny(item any, keys []string) any {
+	for _, k := range keys {
+		if v, ok := Lookup(item, k); ok && v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// Lookup resolves a dotted path in decoded JSON. Arrays met along the way are
+// mapped over, collecting the values found in each element.
+func Lookup(v any, path string) (any, bool) {
+	if path == "" {
+		return v, true
+	}
+	head, rest, _ := strings.Cut(path, ".")
+	switch t := v.(type) {
+	case map[string]any:
+		child, ok := t[head]
+		if !ok {
+			return nil, false
+		}
+		return Lookup(child, rest)
+	case []any:
+		var out []any
+		for _, el := range t {
+			if x, ok := Lookup(el, path); ok {
+				out = append(out, x)
+			}
+		}
+		return out, len(out) > 0
+	}
+	return nil, false
+}
+
+// cell renders a decoded JSON value for a table cell.
+func cell(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "-"
+	case string:
+		if t == "" {
+			return "-"
+		}
+		if ts, ok := looksLikeTime(t); ok {
+			return HumanTime(ts)
+		}
+		return t
+	case bool:
+		return strconv.FormatBool(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case []any:
+		if len(t) == 0 {
+			return "-"
+		}
+		parts := make([]string, len(t))
+		for i, el := range t {
+			if m, ok := el.(map[string]any); ok {
+				if n, ok := m["name"]; ok {
+					parts[i] = cell(n)
+					continue
+				}
+			}
+			parts[i] = cell(el)
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		if len(t) == 0 {
+			return "-"
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k + "=" + cell(t[k])
+		}
+		return strings.Join(parts, ",")
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}
@@ -0,0 +1,127 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Template-based formats take their argument after '=' (kubectl style).
+const (
+	GoTemplate     = "go-template"
+	GoTemplateFile = "go-template-file"
+	JSONPath       = "jsonpath"
+	JSONPathFile   = "jsonpath-file"
+)
+
+// splitFormat splits "go-template=..." into its name and argument.
+func splitFormat(format string) (name, arg string) {
+	name, arg, _ = strings.Cut(format, "=")
+	return strings.ToLower(strings.TrimSpace(name)), arg
+}
+
+// isTemplateFormat reports whether name takes a template argument.
+func isTemplateFormat(name string) bool {
+	switch name {
+	case GoTemplate, GoTemplateFile, JSONPath, JSONPathFile:
+		return true
+	}
+	return false
+}
+
+// templateFuncs are available in -o go-template.
+var templateFuncs = template.FuncMap{
+	"join": func(sep string, v any) string {
+		items, ok := v.([]any)
+		if !ok {
+			return field(v, ',')
+		}
+		parts := make([]string, len(items))
+		for i, it := range items {
+			parts[i] = field(it, ',')
+		}
+		return strings.Join(parts, sep)
+	},
+	"upper": func(v any) string { return strings.ToUpper(field(v, ',')) },
+	"lower": func(v any) string { return strings.ToLower(field(v, ',')) },
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"default": func(def, v any) any {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	// duration formats seconds (number) or a Go duration string.
+	"duration": func(v any) (string, error) {
+		switch t := v.(type) {
+		case float64:
+			return HumanDuration(time.Duration(t * float64(time.Second))), nil
+		case int:
+			return HumanDuration(time.Duration(t) * time.Second), nil
+		case string:
+			d, err := time.ParseDuration(t)
+			if err != nil {
+				return "", err
+			}
+			return HumanDuration(d), nil
+		}
+		return "", fmt.Errorf("duration: unsupported value %v", v)
+	},
+	// humantime renders a timestamp per --tz/--utc/--absolute.
+	"humantime": func(v any) string {
+		s, _ := v.(string)
+		t, ok := ParseTime(s)
+		if !ok {
+			return field(v, ',')
+		}
+		return HumanTime(t)
+	},
+	// ago renders a timestamp relative to now ("in 23m", "4m ago").
+	"ago": func(v any) string {
+		s, _ := v.(string)
+		t, ok := ParseTime(s)
+		if !ok {
+			return field(v, ',')
+		}
+		return Relative(t, time.Now())
+	},
+}
+
+// writeGoTemplate executes a text/template against the decoded body.
+func writeGoTemplate(w io.Writer, v any, text string) error {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("parse go-template: %w", err)
+	}
+	if err := tmpl.Execute(w, v); err != nil {
+		return fmt.Errorf("execute go-template: %w", err)
+	}
+	return nil
+}
+
+// writeTemplate dispatches a template format (name=arg).
+func writeTemplate(w io.Writer, v any, name, arg string) error {
+	if arg == "" {
+		return fmt.Errorf("-o %s requires an argument (-o %s=...)", name, name)
+	}
+	if name == GoTemplateFile || name == JSONPathFile {
+		b, err := os.ReadFile(arg)
+		if err != nil {
+			return err
+		}
+		arg = string(b)
+	}
+	switch name {
+	case GoTemplate, GoTemplateFile:
+		return writeGoTemplate(w, v, arg)
+	default:
+		return writeJSONPath(w, v, arg)
+	}
+}
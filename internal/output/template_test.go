@@ -0,0 +1,125 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoTemplate(t *testing.T) {
+	SetTimeDisplay(TimeDisplay{Location: time.UTC, Absolute: true})
+	t.Cleanup(func() { SetTimeDisplay(TimeDisplay{}) })
+	machines := decodeJSON(t, machinesJSON)
+	hourAgo := time.Now().Add(-90 * time.Minute).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name     string
+		template string
+		input    any
+		want     string
+	}{
+		{"field", "{{(index . 0).name}}", machines, "alpine-1"},
+		{"range", "{{range .}}{{.name}}:{{.port}} {{end}}", machines, "alpine-1:22221 alpine-2:22222 deb-1:22301 "},
+		{"nested field", "{{range .}}{{.labels.os}},{{end}}", machines, "alpine,alpine,debian,"},
+		{"if", `{{range .}}{{if .reserved}}{{.name}}{{end}}{{end}}`, machines, "alpine-1"},
+		{"join", `{{join ", " .}}`, decodeJSON(t, `["a", 1, true]`), "a, 1, true"},
+		{"join a scalar", `{{join ", " .}}`, "solo", "solo"},
+		{"upper", "{{upper .}}", "alpine", "ALPINE"},
+		{"lower", "{{lower .}}", "Alpine", "alpine"},
+		{"json", "{{json (index . 0).labels}}", machines, `{"os":"alpine"}`},
+		{"default on missing", `{{default "-" (index . 2).reserved_by}}`, machines, "-"},
+		{"default on empty", `{{default "-" .}}`, "", "-"},
+		{"default keeps value", `{{default "-" (index . 0).reserved_by}}`, machines, "alice"},
+		{"duration seconds", "{{duration .}}", 5400.0, "1h30m"},
+		{"duration string", "{{duration .}}", "49h", "2d1h"},
+		{"humantime", "{{humantime .}}", "2026-10-19T14:05:00Z", "2026-10-19 14:05 UTC"},
+		{"humantime without zone", "{{humantime .}}", "2026-10-19T14:05:00", "2026-10-19 14:05 UTC"},
+		{"humantime on non-time", "{{humantime .}}", "soon", "soon"},
+		{"ago", "{{ago .}}", hourAgo, "1h30m ago"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := writeGoTemplate(&sb, tt.input, tt.template); err != nil {
+				t.Fatalf("writeGoTemplate(%q): %v", tt.template, err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("writeGoTemplate(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		input    any
+		wantErr  string
+	}{
+		{"parse", "{{.name", nil, "parse go-template"},
+		{"unknown function", "{{nope .}}", nil, "parse go-template"},
+		{"bad duration", "{{duration .}}", "soon", "execute go-template"},
+		{"unsupported duration", "{{duration .}}", true, "duration: unsupported value true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := writeGoTemplate(&sb, tt.input, tt.template)
+			if err == nil {
+				t.Fatalf("writeGoTemplate(%q) succeeded, want an error", tt.template)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("writeGoTemplate(%q) error = %q, want it to contain %q", tt.template, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	machines := decodeJSON(t, machinesJSON)
+	dir := t.TempDir()
+	goFile := filepath.Join(dir, "names.tmpl")
+	jpFile := filepath.Join(dir, "names.jsonpath")
+	if err := os.WriteFile(goFile, []byte("{{range .}}{{.name}}\n{{end}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jpFile, []byte(`{range .items[*]}{.name}{"\n"}{end}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want := "alpine-1\nalpine-2\ndeb-1\n"
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{GoTemplate + "={{range .}}{{.name}}\n{{end}}", want},
+		{GoTemplateFile + "=" + goFile, want},
+		{JSONPath + "={.items[*].name}", "alpine-1 alpine-2 deb-1\n"},
+		{JSONPathFile + "=" + jpFile, want},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			name, arg := splitFormat(tt.format)
+			if !isTemplateFormat(name) {
+				t.Fatalf("isTemplateFormat(%q) = false", name)
+			}
+			var sb strings.Builder
+			if err := writeTemplate(&sb, machines, name, arg); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if err := writeTemplate(&strings.Builder{}, machines, JSONPath, ""); err == nil || !strings.Contains(err.Error(), "requires an argument") {
+		t.Errorf("empty jsonpath argument: err = %v", err)
+	}
+	if err := writeTemplate(&strings.Builder{}, machines, GoTemplateFile, filepath.Join(dir, "missing")); err == nil {
+		t.Error("missing go-template file: no error")
+	}
+}
@@ -0,0 +1,87 @@
+package output
+
+import (
+	"fmt"
+	"time"
+)
+
+// HumanDuration renders d compactly, e.g. "45s", "23m", "2h5m", "3d4h".
+func HumanDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		h := int(d.Hours())
+		if m := int(d.Minutes()) % 60; m != 0 {
+			return fmt.Sprintf("%dh%dm", h, m)
+		}
+		return fmt.Sprintf("%dh", h)
+	default:
+		days := int(d.Hours()) / 24
+		if h := int(d.Hours()) % 24; h != 0 {
+			return fmt.Sprintf("%dd%dh", days, h)
+		}
+		return fmt.Sprintf("%dd", days)
+	}
+}
+
+// Relative describes t relative to now: "in 23m" or "4m ago".
+func Relative(t, now time.Time) string {
+	d := t.Sub(now)
+	if d >= 0 {
+		return "in " + HumanDuration(d)
+	}
+	return HumanDuration(d) + " ago"
+}
+
+// TimeDisplay controls how timestamps are shown in human-oriented formats
+// (table, wide, templates). Machine-readable formats keep RFC 3339.
+type TimeDisplay struct {
+	Location *time.Location // nil means local time
+	Absolute bool           // omit the relative part ("in 23m")
+}
+
+var timeDisplay TimeDisplay
+
+// SetTimeDisplay sets the time display used by table cells and templates.
+func SetTimeDisplay(td TimeDisplay) {
+	timeDisplay = td
+}
+
+// HumanTime renders t in the configured zone, followed by the relative time
+// unless Absolute is set: "2026-10-19 14:05 CEST (in 23m)".
+func HumanTime(t time.Time) string {
+	loc := timeDisplay.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	s := t.In(loc).Format("2006-01-02 15:04 MST")
+	if timeDisplay.Absolute {
+		return s
+	}
+	return s + " (" + Relative(t, time.Now()) + ")"
+}
+
+// looksLikeTime reports whether s is a timestamp worth humanising.
+func looksLikeTime(s string) (time.Time, bool) {
+	if len(s) < 19 || s[4] != '-' || s[7] != '-' || (s[10] != 'T' && s[10] != ' ') {
+		return time.Time{}, false
+	}
+	return ParseTime(s)
+}
+
+// ParseTime accepts RFC 3339 timestamps with or without a zone (the API may
+// omit it; those are taken as UTC).
+func ParseTime(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,148 @@
+package rewrite
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+)
+
+// Rule maps hosts to a target. Exactly one of Exact, CIDR and Regex is set.
+// An empty Target means the engine's default target (the Docker host name).
+// A Regex rule replaces the matched part of the host, like
+// regexp.ReplaceAllString: its target may use $1, ${name} for submatches,
+// and ^...$ anchors replace the whole host.
+type Rule struct {
+	Exact  string `yaml:"exact,omitempty" json:"exact,omitempty"`
+	CIDR   string `yaml:"cidr,omitempty" json:"cidr,omitempty"`
+	Regex  string `yaml:"regex,omitempty" json:"regex,omitempty"`
+	Target string `yaml:"target,omitempty" json:"target,omitempty"`
+}
+
+// String describes the rule's match, e.g. "cidr 127.0.0.0/8".
+func (r Rule) String() string {
+	switch {
+	case r.Exact != "":
+		return "exact " + r.Exact
+	case r.CIDR != "":
+		return "cidr " + r.CIDR
+	default:
+		return "regex " + r.Regex
+	}
+}
+
+// DefaultRules are the loopback and wildcard addresses a container cannot
+// reach as-is: they are sent to the Docker host instead.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Exact: "localhost"},
+		{CIDR: "127.0.0.0/8"},
+		{CIDR: "::1/128"},
+		{CIDR: "0.0.0.0/32"},
+		{CIDR: "::/128"},
+	}
+}
+
+// Applied records one rewrite, for reporting.
+type Applied struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Rule string `json:"rule"`
+}
+
+func (a Applied) String() string {
+	return fmt.Sprintf("%s -> %s (%s)", a.From, a.To, a.Rule)
+}
+
+type compiled struct {
+	rule   Rule
+	prefix netip.Prefix
+	re     *regexp.Regexp
+}
+
+// Engine applies rules in order; the first match wins.
+type Engine struct {
+	rules         []compiled
+	defaultTarget string
+}
+
+// New compiles rules. defaultTarget replaces empty rule targets.
+func New(rules []Rule, defaultTarget string) (*Engine, error) {
+	e := &Engine{defaultTarget: defaultTarget}
+	for i, r := range rules {
+		c := compiled{rule: r}
+		set := 0
+		if r.Exact != "" {
+			set++
+		}
+		if r.CIDR != "" {
+			set++
+			p, err := netip.ParsePrefix(r.CIDR)
+			if err != nil {
+				return nil, fmt.Errorf("host_rewrites[%d]: %w", i, err)
+			}
+			c.prefix = p.Masked()
+		}
+		if r.Regex != "" {
+			set++
+			re, err := regexp.Compile(r.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("host_rewrites[%d]: %w", i, err)
+			}
+			c.re = re
+		}
+		if set != 1 {
+			return nil, fmt.Errorf("host_rewrites[%d]: set exactly one of exact, cidr, regex", i)
+		}
+		if r.Target == "" && defaultTarget == "" {
+			return nil, fmt.Errorf("host_rewrites[%d]: no target and no default target", i)
+		}
+		e.rules = append(e.rules, c)
+	}
+	return e, nil
+}
+
+// Rewrite returns the rewritten host and the rewrite applied, or host
+// unchanged and nil when no rule matches. A nil engine rewrites nothing.
+func (e *Engine) Rewrite(host string) (string, *Applied) {
+	if e == nil {
+		return host, nil
+	}
+	h := strings.TrimSpace(host)
+	bare := strings.TrimSuffix(strings.TrimPrefix(h, "["), "]")
+	addr, addrErr := netip.ParseAddr(bare)
+	if addrErr == nil {
+		addr = addr.Unmap().WithZone("")
+	}
+
+	for _, c := range e.rules {
+		target := c.rule.Target
+		if target == "" {
+			target = e.defaultTarget
+		}
+		switch {
+		case c.rule.Exact != "":
+			if !strings.EqualFold(bare, strings.TrimSuffix(strings.TrimPrefix(c.rule.Exact, "["), "]")) {
+				continue
+			}
+		case c.re != nil:
+			if !c.re.MatchString(h) {
+				continue
+			}
+			if c.rule.Target != "" {
+				target = c.re.ReplaceAllString(h, c.rule.Target)
+			} else {
+				target = c.re.ReplaceAllLiteralString(h, target)
+			}
+		default:
+			if addrErr != nil || !c.prefix.Contains(addr) {
+				continue
+			}
+		}
+		if target == host {
+			return host, nil
+		}
+		return target, &Applied{From: host, To: target, Rule: c.rule.String()}
+	}
+	return host, nil
+}
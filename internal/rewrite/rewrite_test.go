@@ -0,0 +1,169 @@
+package rewrite
+
+import (
+	"strings"
+	"testing"
+)
+
+const dockerHost = "host.docker.internal"
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []Rule
+		host     string
+		want     string
+		wantRule string // "" means no rewrite
+	}{
+		// Exact rules.
+		{"exact", []Rule{{Exact: "localhost"}}, "localhost", dockerHost, "exact localhost"},
+		{"exact ignores case", []Rule{{Exact: "localhost"}}, "LocalHost", dockerHost, "exact localhost"},
+		{"exact trims spaces", []Rule{{Exact: "localhost"}}, " localhost ", dockerHost, "exact localhost"},
+		{"exact with target", []Rule{{Exact: "db.lan", Target: "10.0.0.5"}}, "db.lan", "10.0.0.5", "exact db.lan"},
+		{"exact bracketed", []Rule{{Exact: "[::1]"}}, "::1", dockerHost, "exact [::1]"},
+		{"exact no match", []Rule{{Exact: "localhost"}}, "localhost.example", "localhost.example", ""},
+
+		// CIDR rules.
+		{"cidr v4", []Rule{{CIDR: "127.0.0.0/8"}}, "127.1.2.3", dockerHost, "cidr 127.0.0.0/8"},
+		{"cidr v4 outside", []Rule{{CIDR: "127.0.0.0/8"}}, "128.0.0.1", "128.0.0.1", ""},
+		{"cidr unmasked prefix", []Rule{{CIDR: "10.1.2.3/16"}}, "10.1.200.7", dockerHost, "cidr 10.1.2.3/16"},
+		{"cidr v6", []Rule{{CIDR: "::1/128"}}, "::1", dockerHost, "cidr ::1/128"},
+		{"cidr bracketed v6", []Rule{{CIDR: "::1/128"}}, "[::1]", dockerHost, "cidr ::1/128"},
+		{"cidr v4-mapped v6", []Rule{{CIDR: "127.0.0.0/8"}}, "::ffff:127.0.0.1", dockerHost, "cidr 127.0.0.0/8"},
+		{"cidr bracketed v4-mapped", []Rule{{CIDR: "127.0.0.0/8"}}, "[::ffff:127.0.0.1]", dockerHost, "cidr 127.0.0.0/8"},
+		{"cidr zoned v6", []Rule{{CIDR: "fe80::/10"}}, "fe80::1%eth0", dockerHost, "cidr fe80::/10"},
+		{"cidr zoned loopback", []Rule{{CIDR: "::1/128"}}, "[::1%lo]", dockerHost, "cidr ::1/128"},
+		{"cidr unspecified", []Rule{{CIDR: "0.0.0.0/32"}}, "0.0.0.0", dockerHost, "cidr 0.0.0.0/32"},
+		{"cidr ignores names", []Rule{{CIDR: "127.0.0.0/8"}}, "localhost", "localhost", ""},
+
+		// Regex rules.
+		{
+			"regex with submatch target",
+			[]Rule{{Regex: `^(.+)\.local$`, Target: "$1.lab.example"}},
+			"web.local", "web.lab.example", `regex ^(.+)\.local$`,
+		},
+		{
+			"regex with named submatch",
+			[]Rule{{Regex: `^node-(?P<n>\d+)$`, Target: "10.0.0.${n}"}},
+			"node-12", "10.0.0.12", `regex ^node-(?P<n>\d+)$`,
+		},
+		{
+			"regex replaces the matched part only",
+			[]Rule{{Regex: `\.local$`, Target: ".lan"}},
+			"web.local", "web.lan", `regex \.local$`,
+		},
+		{
+			"regex without target uses the default literally",
+			[]Rule{{Regex: `^localhost$`}},
+			"localhost", dockerHost, "regex ^localhost$",
+		},
+		{
+			"regex without target keeps the rest of the host",
+			[]Rule{{Regex: `^127\.0\.0\.1`}},
+			"127.0.0.1.nip.io", dockerHost + ".nip.io", `regex ^127\.0\.0\.1`,
+		},
+		{"regex no match", []Rule{{Regex: `^db-`}}, "web-1", "web-1", ""},
+
+		// Ordering.
+		{
+			"first match wins",
+			[]Rule{{Exact: "localhost", Target: "first"}, {Exact: "localhost", Target: "second"}},
+			"localhost", "first", "exact localhost",
+		},
+		{
+			"user rule before defaults",
+			append([]Rule{{CIDR: "127.0.0.2/32", Target: "10.0.0.2"}}, DefaultRules()...),
+			"127.0.0.2", "10.0.0.2", "cidr 127.0.0.2/32",
+		},
+		{
+			"falls through to later rules",
+			append([]Rule{{Exact: "db.lan", Target: "10.0.0.5"}}, DefaultRules()...),
+			"127.0.0.1", dockerHost, "cidr 127.0.0.0/8",
+		},
+		{
+			"matching rule with the same target stops",
+			[]Rule{{Exact: "web", Target: "web"}, {Exact: "web", Target: "other"}},
+			"web", "web", "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.rules, dockerHost)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, applied := e.Rewrite(tt.host)
+			if got != tt.want {
+				t.Errorf("Rewrite(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+			switch {
+			case tt.wantRule == "" && applied != nil:
+				t.Errorf("Rewrite(%q) applied %v, want no rewrite", tt.host, applied)
+			case tt.wantRule != "" && applied == nil:
+				t.Errorf("Rewrite(%q) applied nothing, want %s", tt.host, tt.wantRule)
+			case applied != nil:
+				if applied.Rule != tt.wantRule || applied.From != tt.host || applied.To != tt.want {
+					t.Errorf("Rewrite(%q) applied %+v, want rule %q", tt.host, *applied, tt.wantRule)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultRules(t *testing.T) {
+	e, err := New(DefaultRules(), dockerHost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []string{"localhost", "127.0.0.1", "127.0.1.1", "::1", "[::1]", "::ffff:127.0.0.1", "0.0.0.0", "::"} {
+		if got, _ := e.Rewrite(h); got != dockerHost {
+			t.Errorf("Rewrite(%q) = %q, want %q", h, got, dockerHost)
+		}
+	}
+	for _, h := range []string{"10.0.0.1", "example.com", "::2", "fe80::1", ""} {
+		if got, applied := e.Rewrite(h); got != h || applied != nil {
+			t.Errorf("Rewrite(%q) = %q, %v, want it unchanged", h, got, applied)
+		}
+	}
+}
+
+func TestNilEngine(t *testing.T) {
+	var e *Engine
+	if got, applied := e.Rewrite("localhost"); got != "localhost" || applied != nil {
+		t.Errorf("nil engine rewrote localhost to %q (%v)", got, applied)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		rules         []Rule
+		defaultTarget string
+		wantErr       string
+	}{
+		{"nothing set", []Rule{{Target: "x"}}, dockerHost, "host_rewrites[0]: set exactly one of exact, cidr, regex"},
+		{"two set", []Rule{{Exact: "a", CIDR: "10.0.0.0/8"}}, dockerHost, "set exactly one"},
+		{"bad cidr", []Rule{{CIDR: "10.0.0.0/33"}}, dockerHost, "host_rewrites[0]:"},
+		{"address without length", []Rule{{CIDR: "10.0.0.1"}}, dockerHost, "host_rewrites[0]:"},
+		{"bad regex", []Rule{{Exact: "a"}, {Regex: "("}}, dockerHost, "host_rewrites[1]:"},
+		{"no target", []Rule{{Exact: "localhost"}}, "", "no target and no default target"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.rules, tt.defaultTarget)
+			if err == nil {
+				t.Fatal("New succeeded, want an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppliedString(t *testing.T) {
+	a := Applied{From: "localhost", To: dockerHost, Rule: "exact localhost"}
+	if got, want := a.String(), "localhost -> host.docker.internal (exact localhost)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+package secretinput
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Source describes where a secret comes from. At most one field should be set;
+// when none is, the secret is prompted for on the terminal.
+type Source struct {
+	Value string // passed directly as a flag (visible in the process list)
+	Stdin bool   // read from STDIN
+	File  string // read from a file
+	Env   string // read from the named environment variable
+	Cmd   string // output of a shell command (e.g. a password manager)
+}
+
+// count returns how many sources are set.
+func (s Source) count() int {
+	n := 0
+	if s.Value != "" {
+		n++
+	}
+	if s.Stdin {
+		n++
+	}
+	if s.File != "" {
+		n++
+	}
+	if s.Env != "" {
+		n++
+	}
+	if s.Cmd != "" {
+		n++
+	}
+	return n
+}
+
+// Provided reports whether a non-interactive source was given.
+func (s Source) Provided() bool {
+	return s.count() > 0
+}
+
+// Plain reports whether the secret was passed as a plain flag value.
+func (s Source) Plain() bool {
+	return s.Value != ""
+}
+
+// Read returns the secret from the configured non-interactive source.
+// Trailing newlines are stripped from stdin, file and command output.
+func (s Source) Read() (string, error) {
+	if s.count() > 1 {
+		return "", errors.New("only one secret source may be given")
+	}
+	var (
+		v   string
+		src string
+	)
+	switch {
+	case s.Value != "":
+		return s.Value, nil
+	case s.Stdin:
+		src = "stdin"
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("reading secret from stdin: %w", err)
+		}
+		v = string(b)
+	case s.File != "":
+		src = s.File
+		b, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("reading secret file: %w", err)
+		}
+		v = string(b)
+	case s.Env != "":
+		src = "$" + s.Env
+		e, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+		v = e
+	case s.Cmd != "":
+		src = "command"
+		out, err := runCmd(s.Cmd)
+		if err != nil {
+			return "", err
+		}
+		v = out
+	default:
+		return "", errors.New("no secret source given")
+	}
+	v = strings.TrimRight(v, "\r\n")
+	if v == "" {
+		return "", fmt.Errorf("secret from %s is empty", src)
+	}
+	return v, nil
+}
+
+func runCmd(command string) (string, error) {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", command)
+	} else {
+		c = exec.Command("sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	c.Stdin = os.Stdin
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("secret command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("secret command failed: %w", err)
+	}
+	return string(out), nil
+}
+
+// IsTerminal reports whether STDIN is an interactive terminal.
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// Prompt reads a secret from the terminal without echo. Prompts go to STDERR so
+// they never end up in redirected output. When confirm is set the secret is
+// asked for twice and must match.
+func Prompt(label string, confirm bool) (string, error) {
+	if !IsTerminal() {
+		return "", errors.New("stdin is not a TTY")
+	}
+	fd := int(os.Stdin.Fd())
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	p1, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", strings.ToLower(label), err)
+	}
+	if len(p1) == 0 {
+		return "", fmt.Errorf("%s cannot be empty", strings.ToLower(label))
+	}
+	if confirm {
+		fmt.Fprintf(os.Stderr, "Confirm %s: ", strings.ToLower(label))
+		p2, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("reading confirmation: %w", err)
+		}
+		if string(p1) != string(p2) {
+			return "", errors.New("values do not match")
+		}
+	}
+	return string(p1), nil
+}
+
+// ReadLine prompts for a visible value (e.g. a username) on the terminal.
+func ReadLine(label string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	s, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(s), nil
+}
@@ -126,3 +126,45 @@ func keyringAvailable() bool {
 func KeyNameFor(base, prefix string) string {
 	return fmt.Sprintf("api:%s", base)
 }
+
+// Credentials are the username/password used for automatic re-login.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// CredentialKeyFor builds the keychain key holding credentials for an API base.
+func CredentialKeyFor(base string) string {
+	return fmt.Sprintf("cred:%s", base)
+}
+
+// SaveCredentials stores credentials in the OS keychain. There is deliberately
+// no file fallback: passwords are never written to disk by the CLI.
+func SaveCredentials(keyName string, c Credentials) error {
+	if !keyringAvailable() {
+		return errors.New("OS keychain unavailable")
+	}
+	b, _ := json.Marshal(c)
+	return keyring.Set(serviceName, keyName, string(b))
+}
+
+// LoadCredentials reads credentials previously stored with SaveCredentials.
+func LoadCredentials(keyName string) (Credentials, error) {
+	secret, err := keyring.Get(serviceName, keyName)
+	if err != nil {
+		return Credentials{}, err
+	}
+	var c Credentials
+	if err := json.Unmarshal([]byte(secret), &c); err != nil {
+		return Credentials{}, err
+	}
+	return c, nil
+}
+
+// DeleteCredentials removes stored credentials; a missing entry is not an error.
+func DeleteCredentials(keyName string) error {
+	if err := keyring.Delete(serviceName, keyName); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package sshcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jeomhps/projet-iac-cli/internal/sshkey"
+	"golang.org/x/crypto/ssh"
+)
+
+// Target is one machine to check.
+type Target struct {
+	Name     string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	// PrivateKey (PEM) and its Passphrase, tried before the password.
+	PrivateKey string
+	Passphrase string
+}
+
+// Result is the outcome of checking one machine. Reachable means the SSH
+// handshake got as far as the server's host key; AuthOK means the key or
+// password login succeeded.
+type Result struct {
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	Reachable bool   `json:"reachable"`
+	AuthOK    bool   `json:"auth_ok"`
+	HostKey   string `json:"host_key,omitempty"`
+	KeyType   string `json:"key_type,omitempty"`
+	Latency   string `json:"latency,omitempty"`
+	LatencyMS int64  `json:"latency_ms,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// OK reports whether the machine is reachable and the login worked.
+func (r Result) OK() bool { return r.Reachable && r.AuthOK }
+
+// DialFunc opens the TCP connection to a machine.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Checker runs SSH checks. The zero value dials with net.Dialer and a 10s
+// timeout per machine.
+type Checker struct {
+	Dial    DialFunc
+	Timeout time.Duration
+}
+
+// Check connects to t, performs the SSH handshake and a key or password
+// login, and reports what worked. It never returns an error: failures are in Result.
+func (c Checker) Check(ctx context.Context, t Target) Result {
+	addr := net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+	r := Result{Name: t.Name, Address: addr}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dial := c.Dial
+	if dial == nil {
+		var d net.Dialer
+		dial = d.DialContext
+	}
+
+	start := time.Now()
+	conn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	defer conn.Close()
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+	}
+
+	// An unusable key is a credentials problem, not a connectivity one: the
+	// handshake still runs, with the password only.
+	var auth []ssh.AuthMethod
+	var keyErr error
+	if t.PrivateKey != "" {
+		signer, err := sshkey.Parse([]byte(t.PrivateKey), t.Passphrase)
+		if err != nil {
+			keyErr = err
+		} else {
+			auth = append(auth, ssh.PublicKeys(signer))
+		}
+	}
+
+	var keySeen bool
+	cfg := &ssh.ClientConfig{
+		User: t.User,
+		Auth: append(auth,
+			ssh.Password(t.Password),
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = t.Password
+				}
+				return answers, nil
+			}),
+		),
+		// We report the key instead of verifying it: the check is about
+		// reachability and credentials, not trust.
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			keySeen = true
+			r.HostKey = ssh.FingerprintSHA256(key)
+			r.KeyType = key.Type()
+			return nil
+		},
+		Timeout: timeout,
+	}
+
+	sc, chans, reqs, err := ssh.NewClientConn(conn, addr, cfg)
+	elapsed := time.Since(start)
+	r.Reachable = keySeen
+	if r.Reachable {
+		r.Latency = elapsed.Round(time.Millisecond).String()
+		r.LatencyMS = elapsed.Milliseconds()
+	}
+	if err != nil {
+		r.Error = describe(err)
+		if keyErr != nil && r.Reachable {
+			r.Error = keyErr.Error()
+		}
+		return r
+	}
+	go ssh.DiscardRequests(reqs)
+	go func() {
+		for ch := range chans {
+			_ = ch.Reject(ssh.Prohibited, "")
+		}
+	}()
+	_ = sc.Close()
+	r.AuthOK = true
+	if keyErr != nil {
+		r.Error = keyErr.Error() + " (logged in with the password)"
+	}
+	return r
+}
+
+// CheckAll checks targets with at most parallel checks at a time. Results
+// are in the order of targets.
+func (c Checker) CheckAll(ctx context.Context, targets []Target, parallel int) []Result {
+	if parallel <= 0 {
+		parallel = 1
+	}
+	results := make([]Result, len(targets))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = c.Check(ctx, t)
+		}(i, t)
+	}
+	wg.Wait()
+	return results
+}
+
+// describe shortens the ssh package's errors to what an operator acts on.
+func describe(err error) string {
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return "timed out"
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "unable to authenticate") {
+		return "authentication failed"
+	}
+	return strings.TrimPrefix(msg, "ssh: ")
+}
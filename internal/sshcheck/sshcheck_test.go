@@ -0,0 +1,247 @@
+package sshcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	testUser     = "root"
+	testPassword = "s3cret"
+)
+
+// testServer is an in-process SSH server accepting testPassword and one
+// client key for testUser.
+type testServer struct {
+	addr    string
+	hostKey ssh.PublicKey
+}
+
+func startServer(t *testing.T, clientKey ssh.PublicKey) *testServer {
+	t.Helper()
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == testUser && string(pass) == testPassword {
+				return nil, nil
+			}
+			return nil, errors.New("denied")
+		},
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if c.User() == testUser && bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	cfg.AddHostKey(hostSigner)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					_ = ch.Reject(ssh.Prohibited, "")
+				}
+			}()
+		}
+	}()
+	return &testServer{addr: ln.Addr().String(), hostKey: hostSigner.PublicKey()}
+}
+
+// dialTo sends every connection to addr, whatever the target host.
+func dialTo(addr string) DialFunc {
+	return func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	}
+}
+
+func newClientKey(t *testing.T) (pemKey string, pub ssh.PublicKey) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(block)), signer.PublicKey()
+}
+
+func TestCheck(t *testing.T) {
+	clientKey, clientPub := newClientKey(t)
+	otherKey, _ := newClientKey(t)
+	srv := startServer(t, clientPub)
+	c := Checker{Dial: dialTo(srv.addr), Timeout: 5 * time.Second}
+
+	tests := []struct {
+		name     string
+		target   Target
+		wantAuth bool
+		wantErr  string
+	}{
+		{
+			name:     "password",
+			target:   Target{Name: "pw", User: testUser, Password: testPassword},
+			wantAuth: true,
+		},
+		{
+			name:     "key",
+			target:   Target{Name: "key", User: testUser, PrivateKey: clientKey},
+			wantAuth: true,
+		},
+		{
+			name:     "unknown key falls back to password",
+			target:   Target{Name: "fallback", User: testUser, PrivateKey: otherKey, Password: testPassword},
+			wantAuth: true,
+		},
+		{
+			name:    "unparsable key",
+			target:  Target{Name: "badkey", User: testUser, PrivateKey: "not a key"},
+			wantErr: "private key: ssh: no key found",
+		},
+		{
+			name:     "unparsable key, password works",
+			target:   Target{Name: "badkey-pw", User: testUser, PrivateKey: "not a key", Password: testPassword},
+			wantAuth: true,
+			wantErr:  "private key: ssh: no key found (logged in with the password)",
+		},
+		{
+			name:    "bad password",
+			target:  Target{Name: "badpw", User: testUser, Password: "wrong"},
+			wantErr: "authentication failed",
+		},
+		{
+			name:    "bad user",
+			target:  Target{Name: "baduser", User: "nobody", PrivateKey: clientKey, Password: testPassword},
+			wantErr: "authentication failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.target.Host, tt.target.Port = "machine.test", 22
+			r := c.Check(context.Background(), tt.target)
+			if !r.Reachable {
+				t.Fatalf("Reachable = false, error %q", r.Error)
+			}
+			if r.AuthOK != tt.wantAuth {
+				t.Errorf("AuthOK = %v, want %v (error %q)", r.AuthOK, tt.wantAuth, r.Error)
+			}
+			if r.Error != tt.wantErr {
+				t.Errorf("Error = %q, want %q", r.Error, tt.wantErr)
+			}
+			if r.Address != "machine.test:22" {
+				t.Errorf("Address = %q", r.Address)
+			}
+			if want := ssh.FingerprintSHA256(srv.hostKey); r.HostKey != want {
+				t.Errorf("HostKey = %q, want %q", r.HostKey, want)
+			}
+			if r.KeyType != ssh.KeyAlgoED25519 {
+				t.Errorf("KeyType = %q", r.KeyType)
+			}
+			if r.Latency == "" {
+				t.Error("Latency not set")
+			}
+		})
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	// The peer accepts the connection but never speaks SSH.
+	var peers []net.Conn
+	t.Cleanup(func() {
+		for _, p := range peers {
+			p.Close()
+		}
+	})
+	silent := func(context.Context, string, string) (net.Conn, error) {
+		client, server := net.Pipe()
+		peers = append(peers, server)
+		return client, nil
+	}
+	c := Checker{Dial: silent, Timeout: 200 * time.Millisecond}
+
+	start := time.Now()
+	r := c.Check(context.Background(), Target{Name: "silent", Host: "machine.test", Port: 22, User: testUser, Password: testPassword})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Check took %s, want about the 200ms timeout", elapsed)
+	}
+	if r.Reachable || r.AuthOK {
+		t.Errorf("Reachable = %v, AuthOK = %v, want both false", r.Reachable, r.AuthOK)
+	}
+	if r.Error != "timed out" {
+		t.Errorf("Error = %q, want %q", r.Error, "timed out")
+	}
+}
+
+func TestCheckDialError(t *testing.T) {
+	blocked := func(ctx context.Context, _, _ string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	c := Checker{Dial: blocked, Timeout: 100 * time.Millisecond}
+	r := c.Check(context.Background(), Target{Name: "down", Host: "machine.test", Port: 22})
+	if r.Reachable || r.Error == "" {
+		t.Errorf("got %+v, want an unreachable result with an error", r)
+	}
+}
+
+func TestCheckAllKeepsOrder(t *testing.T) {
+	_, clientPub := newClientKey(t)
+	srv := startServer(t, clientPub)
+	c := Checker{Dial: dialTo(srv.addr), Timeout: 5 * time.Second}
+
+	targets := []Target{
+		{Name: "a", Host: "a.test", Port: 22, User: testUser, Password: testPassword},
+		{Name: "b", Host: "b.test", Port: 22, User: testUser, Password: "wrong"},
+		{Name: "c", Host: "c.test", Port: 22, User: testUser, Password: testPassword},
+	}
+	results := c.CheckAll(context.Background(), targets, 2)
+	if len(results) != len(targets) {
+		t.Fatalf("got %d results, want %d", len(results), len(targets))
+	}
+	for i, r := range results {
+		if r.Name != targets[i].Name {
+			t.Errorf("results[%d].Name = %q, want %q", i, r.Name, targets[i].Name)
+		}
+		if want := targets[i].Password == testPassword; r.OK() != want {
+			t.Errorf("%s: OK = %v, want %v (error %q)", r.Name, r.OK(), want, r.Error)
+		}
+	}
+}
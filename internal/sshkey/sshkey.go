@@ -0,0 +1,59 @@
+package sshkey
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrPassphraseNeeded is returned for an encrypted key given without a
+// passphrase.
+var ErrPassphraseNeeded = errors.New("private key is encrypted: a passphrase is required")
+
+// ReadFile reads a private key file. A leading ~/ is the home directory, and
+// relative paths are resolved against baseDir when it is not empty.
+func ReadFile(path, baseDir string) ([]byte, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, rest)
+	} else if baseDir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("private key: %w", err)
+	}
+	return b, nil
+}
+
+// Parse checks that pem is a usable private key, decrypting it with
+// passphrase when it is encrypted, and returns its signer.
+func Parse(pem []byte, passphrase string) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey(pem)
+	var missing *ssh.PassphraseMissingError
+	switch {
+	case err == nil:
+		return signer, nil
+	case !errors.As(err, &missing):
+		return nil, fmt.Errorf("private key: %w", err)
+	case passphrase == "":
+		return nil, ErrPassphraseNeeded
+	}
+	signer, err = ssh.ParsePrivateKeyWithPassphrase(pem, []byte(passphrase))
+	if err != nil {
+		return nil, fmt.Errorf("private key: %w", err)
+	}
+	return signer, nil
+}
+
+// Fingerprint returns the SHA256 fingerprint of the signer's public key.
+func Fingerprint(s ssh.Signer) string {
+	return ssh.FingerprintSHA256(s.PublicKey())
+}
@@ -1,9 +1,26 @@
 package types
 
+// MachineCreate is a machine definition. It needs a password or a private
+// key; PrivateKeyFile is read locally into PrivateKey and never sent.
 type MachineCreate struct {
-	Name     string `json:"name" yaml:"name"`
-	Host     string `json:"host" yaml:"host"`
-	Port     int    `json:"port" yaml:"port"`
-	User     string `json:"user" yaml:"user"`
-	Password string `json:"password" yaml:"password"`
+	Name           string            `json:"name" yaml:"name"`
+	Host           string            `json:"host" yaml:"host"`
+	Port           int               `json:"port" yaml:"port"`
+	User           string            `json:"user" yaml:"user"`
+	Password       string            `json:"password,omitempty" yaml:"password,omitempty"`
+	PrivateKey     string            `json:"private_key,omitempty" yaml:"private_key,omitempty"`
+	PrivateKeyFile string            `json:"-" yaml:"private_key_file,omitempty"`
+	Passphrase     string            `json:"passphrase,omitempty" yaml:"passphrase,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// MachineUpdate is a partial machine update; nil fields are left unchanged.
+type MachineUpdate struct {
+	Host     *string `json:"host,omitempty"`
+	Port     *int    `json:"port,omitempty"`
+	User     *string `json:"user,omitempty"`
+	Password *string `json:"password,omitempty"`
+
+	PrivateKey *string `json:"private_key,omitempty"`
+	Passphrase *string `json:"passphrase,omitempty"`
 }
@@ -0,0 +1,61 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Event types emitted between two polls.
+const (
+	Added    = "added"
+	Modified = "modified"
+	Removed  = "removed"
+)
+
+// Event describes one item that changed between polls.
+type Event struct {
+	Type string `json:"type"`
+	Key  string `json:"key"`
+	Item any    `json:"item"`
+}
+
+// KeyOf returns the identity of an item: the value of key, or the whole item
+// encoded as JSON when it has no such field.
+func KeyOf(item any, key string) string {
+	if m, ok := item.(map[string]any); ok {
+		if v, ok := m[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+	}
+	b, _ := json.Marshal(item)
+	return string(b)
+}
+
+// Diff compares two polls by identity key. Added and modified events follow
+// the order of cur; removed events follow the order of prev.
+func Diff(prev, cur []any, key string) []Event {
+	before := make(map[string]any, len(prev))
+	for _, it := range prev {
+		before[KeyOf(it, key)] = it
+	}
+	seen := make(map[string]bool, len(cur))
+	var events []Event
+	for _, it := range cur {
+		k := KeyOf(it, key)
+		seen[k] = true
+		old, existed := before[k]
+		switch {
+		case !existed:
+			events = append(events, Event{Type: Added, Key: k, Item: it})
+		case !reflect.DeepEqual(old, it):
+			events = append(events, Event{Type: Modified, Key: k, Item: it})
+		}
+	}
+	for _, it := range prev {
+		if k := KeyOf(it, key); !seen[k] {
+			events = append(events, Event{Type: Removed, Key: k, Item: it})
+		}
+	}
+	return events
+}